@@ -0,0 +1,87 @@
+package xerrors
+
+import (
+	"iter"
+)
+
+// All iterates over err and all its wrapped errors (recursively), outermost first.
+// Errors wrapping multiple errors (Unwrap() []error) are walked depth-first.
+// The walk stops at cycles and beyond MaxDepth.
+func All(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		w := newWalker(false)
+		w.walk(err, 0, func(_ int, err error) bool {
+			return yield(err)
+		})
+	}
+}
+
+// Layers is All, but additionally yields the depth of each error: 0 for err, 1 for the error it wraps, etc.
+// For single chains the depth is the index of the error in the chain.
+func Layers(err error) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		w := newWalker(false)
+		w.walk(err, 0, yield)
+	}
+}
+
+// AllFunc is All, but only yielding errors for which f returns true.
+func AllFunc(err error, f func(error) bool) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		w := newWalker(false)
+		w.walk(err, 0, func(_ int, err error) bool {
+			return !f(err) || yield(err)
+		})
+	}
+}
+
+// NonFrames is All, but skipping any FrameError.
+func NonFrames(err error) iter.Seq[error] {
+	return AllFunc(err, isNotFrameError)
+}
+
+// Kept is All, but only yielding errors kept by the Serializer.
+// Unlike the Printer, it does not see through errors hidden by Opaque.
+func Kept(err error, s Serializer) iter.Seq[error] {
+	return AllFunc(err, s.Keep)
+}
+
+// Revealed is All, but seeing through errors hidden by Opaque, and yielding a Truncation marker last if the walk
+// is truncated. These are the errors Printers present to Serializers, unless created WithMaxDepth.
+// Like Reveal, it is meant for Serializers walking the chain themselves.
+func Revealed(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		w := newWalker(true)
+		if !w.walk(err, 0, func(_ int, err error) bool {
+			return yield(err)
+		}) {
+			return
+		}
+		if w.truncated() {
+			yield(&truncatedError{omitted: w.omitted, omittedMore: w.omittedMore, cycle: w.cycle})
+		}
+	}
+}
+
+// Depth returns the number of errors in the longest chain within err, 0 if err is nil.
+// For single chains it is the number of errors in it.
+// Like all walks, it is bounded by MaxDepth.
+func Depth(err error) int {
+	var depth int
+	for d := range Layers(err) {
+		if d+1 > depth {
+			depth = d + 1
+		}
+	}
+	return depth
+}
+
+// Count returns the total number of errors in err, including all wrapped errors (recursively).
+// For single chains it is the same as Depth.
+func Count(err error) int {
+	var count int
+	for range All(err) {
+		count++
+	}
+	return count
+}
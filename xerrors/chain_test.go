@@ -0,0 +1,164 @@
+package xerrors_test
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+type multiError struct {
+	errs []error
+}
+
+func (multiError) Error() string { return "multi" }
+
+func (err multiError) Unwrap() []error { return err.errs }
+
+func chainScenarioError() error {
+	return xerrors.Wrap(
+		"outer",
+		multiError{[]error{
+			xerrors.Wrap("left", xerrors.New("left_cause"), xerrors.OmitFrame()),
+			xerrors.New("right"),
+		}},
+		xerrors.OmitFrame(),
+	)
+}
+
+func messages(seq iter.Seq[error]) []string {
+	var out []string
+	for err := range seq {
+		out = append(out, err.Error())
+	}
+	return out
+}
+
+func TestAll(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut []string
+	}{
+		{
+			name:        "nil",
+			err:         nil,
+			expectedOut: nil,
+		},
+		{
+			name:        "chain",
+			err:         xerrors.Wrap("wrapper", xerrors.New("msg"), xerrors.OmitFrame()),
+			expectedOut: []string{"wrapper", "msg"},
+		},
+		{
+			name:        "tree",
+			err:         chainScenarioError(),
+			expectedOut: []string{"outer", "multi", "left", "left_cause", "right"},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if out := messages(xerrors.All(scenario.err)); !reflect.DeepEqual(out, scenario.expectedOut) {
+				t.Fatalf("mismatched outputs, expected %q got %q", scenario.expectedOut, out)
+			}
+		})
+	}
+}
+
+func TestAll_break(t *testing.T) {
+	var out []string
+	for err := range xerrors.All(chainScenarioError()) {
+		out = append(out, err.Error())
+		if err.Error() == "left" {
+			break
+		}
+	}
+
+	if expectedOut := []string{"outer", "multi", "left"}; !reflect.DeepEqual(out, expectedOut) {
+		t.Fatalf("mismatched outputs, expected %q got %q", expectedOut, out)
+	}
+}
+
+func TestLayers(t *testing.T) {
+	var depths []int
+	for depth := range xerrors.Layers(chainScenarioError()) {
+		depths = append(depths, depth)
+	}
+
+	if expectedDepths := []int{0, 1, 2, 3, 2}; !reflect.DeepEqual(depths, expectedDepths) {
+		t.Fatalf("mismatched depths, expected %v got %v", expectedDepths, depths)
+	}
+}
+
+func TestNonFrames(t *testing.T) {
+	err := xerrors.Wrap("wrapper", xerrors.New("msg"))
+
+	if out, expectedOut := messages(xerrors.NonFrames(err)), []string{"wrapper", "msg"}; !reflect.DeepEqual(out, expectedOut) {
+		t.Fatalf("mismatched outputs, expected %q got %q", expectedOut, out)
+	}
+
+	if out, expectedOut := messages(xerrors.Kept(err, xerrors.NewColonBasicSerializer())), []string{"wrapper", "msg"}; !reflect.DeepEqual(out, expectedOut) {
+		t.Fatalf("mismatched Kept outputs, expected %q got %q", expectedOut, out)
+	}
+}
+
+func TestDepthAndCount(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		err           error
+		expectedDepth int
+		expectedCount int
+	}{
+		{
+			name:          "nil",
+			err:           nil,
+			expectedDepth: 0,
+			expectedCount: 0,
+		},
+		{
+			name:          "chain",
+			err:           xerrors.Wrap("wrapper", xerrors.New("msg")),
+			expectedDepth: 3,
+			expectedCount: 3,
+		},
+		{
+			name:          "tree",
+			err:           chainScenarioError(),
+			expectedDepth: 4,
+			expectedCount: 5,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if depth := xerrors.Depth(scenario.err); depth != scenario.expectedDepth {
+				t.Fatalf("mismatched depth, expected %d got %d", scenario.expectedDepth, depth)
+			}
+			if count := xerrors.Count(scenario.err); count != scenario.expectedCount {
+				t.Fatalf("mismatched count, expected %d got %d", scenario.expectedCount, count)
+			}
+		})
+	}
+}
+
+func TestTree(t *testing.T) {
+	err := chainScenarioError()
+
+	if out, expectedOut := xerrors.String(err), "outer: multi: left: left_cause: right"; out != expectedOut {
+		t.Fatalf("mismatched String output, expected %q got %q", expectedOut, out)
+	}
+
+	if cause := xerrors.Cause(err); cause == nil || cause.Error() != "left_cause" {
+		t.Fatalf("expected first cause depth-first, got %v", cause)
+	}
+
+	if !xerrors.Contains(err, xerrors.New("right")) {
+		t.Fatal("expected tree to contain errors in any of its branches")
+	}
+}
@@ -0,0 +1,217 @@
+package xerrors
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// collapsedStep is how the collapsingSerializer handles an error of the walk.
+// The first error of a collapsed group has its size in repeats, and its first FrameError the number of FrameErrors
+// after the first error in frames. All other errors of the group are hidden.
+type collapsedStep struct {
+	hidden  bool
+	repeats int
+	frames  int
+
+	// frame is set for FrameErrors, only required while laying out.
+	frame bool
+}
+
+// collapsedFormat is how a kept error is to be formatted, as per the step it was kept on.
+type collapsedFormat struct {
+	// err is the kept error, only compared to tell truncation markers apart.
+	err error
+	collapsedStep
+}
+
+type collapsingSerializer struct {
+	Decorating
+
+	// reverse is set if Printers present the errors innermost first, see order.
+	reverse  bool
+	maxDepth int
+
+	// root is the error being serialised, as given by the Printer.
+	root error
+
+	// Outermost first, steps holds how each error of the walk is handled, laid out on the first Keep.
+	// next is the number of errors already kept or not, in the order they are presented.
+	laidOut bool
+	steps   []collapsedStep
+	next    int
+
+	// formats holds how the kept errors are to be formatted, from nextFormat onwards.
+	// Keep decides it, as Printers may keep all errors before formatting any, see layoutUser.
+	formats    []collapsedFormat
+	nextFormat int
+}
+
+func (s *collapsingSerializer) Keep(err error) bool {
+	if !s.laidOut {
+		// without a Printer, the first error kept is the outermost
+		root := s.root
+		if root == nil {
+			root = err
+		}
+		s.layout(root)
+	}
+
+	var step collapsedStep
+	if s.next < len(s.steps) {
+		i := s.next
+		if s.reverse {
+			i = len(s.steps) - 1 - s.next
+		}
+		step = s.steps[i]
+		s.next++
+	}
+
+	if step.hidden || !s.Serializer.Keep(err) {
+		return false
+	}
+
+	s.formats = append(s.formats, collapsedFormat{err: err, collapsedStep: step})
+	return true
+}
+
+// layout walks err once, as Printers do, grouping consecutive similar errors.
+// Groups only extend along single chains, never across errors wrapping multiple errors nor past truncated walks.
+// The FrameErrors within a group are hidden too, other than the first one.
+func (s *collapsingSerializer) layout(err error) {
+	s.laidOut = true
+
+	w := newWalker(true)
+	if s.maxDepth > 0 {
+		w = newWalkerDepth(true, s.maxDepth)
+	}
+
+	head, prevDepth := -1, 0
+	var headErr error
+
+	w.walk(err, 0, func(depth int, err error) bool {
+		i := len(s.steps)
+		s.steps = append(s.steps, collapsedStep{frame: IsFrameError(err)})
+
+		if head != -1 && depth != prevDepth+1 {
+			s.collapse(head, i)
+			head = -1
+		}
+		prevDepth = depth
+
+		if s.steps[i].frame {
+			return true
+		}
+
+		_, isMulti := err.(multiWrapper)
+		if head != -1 {
+			if !isMulti && similarLayer(headErr, err) {
+				s.steps[head].repeats++
+				return true
+			}
+			s.collapse(head, i)
+			head = -1
+		}
+
+		if !isMulti {
+			head, headErr = i, err
+			s.steps[i].repeats = 1
+		}
+		return true
+	})
+
+	if head != -1 {
+		s.collapse(head, len(s.steps))
+	}
+}
+
+// collapse hides the errors of the group from head to end (exclusive), other than head and the first FrameError.
+func (s *collapsingSerializer) collapse(head, end int) {
+	if s.steps[head].repeats == 1 {
+		return
+	}
+
+	firstFrame := -1
+	for i := head + 1; i < end; i++ {
+		if firstFrame == -1 && s.steps[i].frame {
+			firstFrame = i
+			continue
+		}
+		s.steps[i].hidden = true
+	}
+
+	if firstFrame != -1 {
+		for i := firstFrame; i < end; i++ {
+			if s.steps[i].frame {
+				s.steps[firstFrame].frames++
+			}
+		}
+	}
+}
+
+func (s *collapsingSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	// the Printer's truncation markers are not kept, they are formatted as by the decorated Serializer
+	if s.nextFormat == len(s.formats) || (IsTruncation(err) && !identical(s.formats[s.nextFormat].err, err)) {
+		return s.Serializer.CustomFormat(err, buf)
+	}
+	format := s.formats[s.nextFormat]
+	s.nextFormat++
+
+	if format.repeats < 2 && format.frames < 2 {
+		return s.Serializer.CustomFormat(err, buf)
+	}
+
+	if !s.Serializer.CustomFormat(err, buf) {
+		buf.WriteString(err.Error())
+	}
+
+	if format.repeats > 1 {
+		buf.WriteString(" (x")
+		buf.WriteString(strconv.Itoa(format.repeats))
+		buf.WriteString(")")
+	} else {
+		buf.WriteString(" +")
+		buf.WriteString(strconv.Itoa(format.frames - 1))
+		buf.WriteString(" frames")
+	}
+
+	return true
+}
+
+func (s *collapsingSerializer) order(reverse bool) bool {
+	s.reverse = s.Decorating.order(reverse)
+	return s.reverse
+}
+
+func (s *collapsingSerializer) useMaxDepth(depth int) {
+	s.maxDepth = depth
+	s.Decorating.useMaxDepth(depth)
+}
+
+func (s *collapsingSerializer) useRoot(err error) {
+	s.root = err
+	s.Decorating.useRoot(err)
+}
+
+func (s *collapsingSerializer) Reset() {
+	s.root = nil
+	s.laidOut = false
+	s.steps = s.steps[:0]
+	s.next = 0
+	clear(s.formats)
+	s.formats = s.formats[:0]
+	s.nextFormat = 0
+	s.Serializer.Reset()
+}
+
+// NewCollapsingSerializer decorates a Serializer so consecutive similar errors are serialised only once.
+// Errors are similar as per Similar, ignoring their wrapped errors, and are serialised as 'msg (xN)'.
+// Such sequences are typically the product of retry or polling loops.
+// Only the errors within the Printer's WithMaxDepth are counted, and it supports Printers created WithReverseOrder.
+//
+// Only the first FrameError of the collapsed errors is kept, if the Serializer keeps FrameErrors at all.
+// It is suffixed with ' +N frames' if the collapsed errors had further FrameErrors.
+func NewCollapsingSerializer(s Serializer) Serializer {
+	return &collapsingSerializer{
+		Decorating: Decorating{Serializer: s},
+	}
+}
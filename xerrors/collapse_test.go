@@ -0,0 +1,166 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+// beware, the line of the Wrap call in this method is required to test frame collapsing logic
+func retryError(attempts int, cause error, opts ...xerrors.WrapOptionFunc) error {
+	err := cause
+	for i := 0; i < attempts; i++ {
+		err = xerrors.Wrap("timeout", err, opts...)
+	}
+	return err
+}
+
+func TestCollapsingSerializer(t *testing.T) {
+	scenarios := []struct {
+		name              string
+		err               error
+		expectedBasicOut  string
+		expectedDetailOut string
+	}{
+		{
+			name:              "noRepeats",
+			err:               xerrors.Wrap("outer", xerrors.New("timeout"), xerrors.OmitFrame()),
+			expectedBasicOut:  "outer: timeout",
+			expectedDetailOut: "outer: timeout",
+		},
+		{
+			name:              "differentTypes",
+			err:               retryError(1, xerrors.New("timeout"), xerrors.OmitFrame()),
+			expectedBasicOut:  "timeout: timeout",
+			expectedDetailOut: "timeout: timeout",
+		},
+		{
+			name:              "repeatsWithoutFrames",
+			err:               xerrors.Wrap("outer", retryError(5, xerrors.New("dial"), xerrors.OmitFrame()), xerrors.OmitFrame()),
+			expectedBasicOut:  "outer: timeout (x5): dial",
+			expectedDetailOut: "outer: timeout (x5): dial",
+		},
+		{
+			name:              "repeatsToTheEnd",
+			err:               retryError(3, nil, xerrors.OmitFrame()),
+			expectedBasicOut:  "timeout (x3)",
+			expectedDetailOut: "timeout (x3)",
+		},
+		{
+			name:              "repeatsWithFrames",
+			err:               retryError(5, xerrors.New("dial")),
+			expectedBasicOut:  "timeout (x5): dial",
+			expectedDetailOut: "timeout (x5)(xerrors_test.retryError:collapse_test.go:14 +4 frames): dial",
+		},
+		{
+			name:              "separateRepeats",
+			err:               retryError(2, xerrors.Wrap("other", retryError(3, nil, xerrors.OmitFrame()), xerrors.OmitFrame()), xerrors.OmitFrame()),
+			expectedBasicOut:  "timeout (x2): other: timeout (x3)",
+			expectedDetailOut: "timeout (x2): other: timeout (x3)",
+		},
+	}
+
+	basicPrinter := xerrors.NewPrinter(func() xerrors.Serializer {
+		return xerrors.NewCollapsingSerializer(xerrors.NewColonBasicSerializer())
+	})
+	detailPrinter := xerrors.NewPrinter(func() xerrors.Serializer {
+		return xerrors.NewCollapsingSerializer(xerrors.NewColonDetailedSerializer())
+	})
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			// twice, to validate Reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if err := basicPrinter.Write(&buf, scenario.err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+				if buf.String() != scenario.expectedBasicOut {
+					t.Fatalf("mismatched basic output, expected %q got %q", scenario.expectedBasicOut, buf.String())
+				}
+
+				buf.Reset()
+				if err := detailPrinter.Write(&buf, scenario.err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+				if buf.String() != scenario.expectedDetailOut {
+					t.Fatalf("mismatched detail output, expected %q got %q", scenario.expectedDetailOut, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCollapsingSerializer_printerOptions(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		printer     *xerrors.Printer
+		err         error
+		expectedOut string
+	}{
+		{
+			name: "reverseOrder",
+			printer: xerrors.NewPrinter(
+				func() xerrors.Serializer { return xerrors.NewCollapsingSerializer(xerrors.NewColonBasicSerializer()) },
+				xerrors.WithReverseOrder(),
+			),
+			err:         xerrors.Wrap("outer", retryError(3, xerrors.New("dial"), xerrors.OmitFrame()), xerrors.OmitFrame()),
+			expectedOut: "dial: timeout (x3): outer",
+		},
+		{
+			name: "reverseSerializer",
+			printer: xerrors.NewPrinter(func() xerrors.Serializer {
+				return xerrors.NewCollapsingSerializer(xerrors.NewColonBasicReverseSerializer())
+			}),
+			err:         xerrors.Wrap("outer", retryError(3, xerrors.New("dial"), xerrors.OmitFrame()), xerrors.OmitFrame()),
+			expectedOut: "dial <- timeout (x3) <- outer",
+		},
+		{
+			name: "reverseSerializerWithFrames",
+			printer: xerrors.NewPrinter(func() xerrors.Serializer {
+				return xerrors.NewCollapsingSerializer(xerrors.NewColonDetailedReverseSerializer())
+			}),
+			err:         xerrors.Wrap("outer", retryError(3, xerrors.New("dial")), xerrors.OmitFrame()),
+			expectedOut: "dial <- timeout (x3)(xerrors_test.retryError:collapse_test.go:14 +2 frames) <- outer",
+		},
+		{
+			name: "maxDepth",
+			printer: xerrors.NewPrinter(
+				func() xerrors.Serializer { return xerrors.NewCollapsingSerializer(xerrors.NewColonBasicSerializer()) },
+				xerrors.WithMaxDepth(3),
+			),
+			err:         retryError(10, xerrors.New("dial"), xerrors.OmitFrame()),
+			expectedOut: "timeout (x3): ...(3+ more)",
+		},
+		{
+			name: "maxDepthReverse",
+			printer: xerrors.NewPrinter(
+				func() xerrors.Serializer { return xerrors.NewCollapsingSerializer(xerrors.NewColonBasicSerializer()) },
+				xerrors.WithMaxDepth(3),
+				xerrors.WithReverseOrder(),
+			),
+			err:         retryError(10, xerrors.New("dial"), xerrors.OmitFrame()),
+			expectedOut: "...(3+ more): timeout (x3)",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			// twice, to validate Reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if err := scenario.printer.Write(&buf, scenario.err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
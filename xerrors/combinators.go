@@ -0,0 +1,151 @@
+package xerrors
+
+import (
+	"bytes"
+	"io"
+)
+
+// Flusher is implemented by Serializers that defer writing some of their output until all errors have been appended.
+// Printers call Flush after the last error is appended, and before Reset.
+// Serializers decorating other Serializers should forward it to them, as Decorating does.
+type Flusher interface {
+	Flush(io.Writer) error
+}
+
+// flush forwards to the Serializer's Flush, if it is a Flusher.
+func flush(s Serializer, w io.Writer) error {
+	if f, ok := s.(Flusher); ok {
+		return f.Flush(w)
+	}
+	return nil
+}
+
+type filterKeepSerializer struct {
+	Decorating
+
+	keep func(error) bool
+}
+
+func (s *filterKeepSerializer) Keep(err error) bool {
+	return s.keep(err) && s.Serializer.Keep(err)
+}
+
+// FilterKeep decorates a Serializer so it only serialises the errors it keeps for which keep returns true.
+func FilterKeep(s Serializer, keep func(error) bool) Serializer {
+	return &filterKeepSerializer{
+		Decorating: Decorating{Serializer: s},
+		keep:       keep,
+	}
+}
+
+type overrideFormatSerializer struct {
+	Decorating
+
+	format func(error, *bytes.Buffer) bool
+}
+
+func (s *overrideFormatSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	return s.format(err, buf) || s.Serializer.CustomFormat(err, buf)
+}
+
+// OverrideFormat decorates a Serializer so errors are formatted with format ahead of the Serializer's CustomFormat.
+// format has the semantics of CustomFormat: if it returns false it must write nothing, and the Serializer's is used.
+func OverrideFormat(s Serializer, format func(error, *bytes.Buffer) bool) Serializer {
+	return &overrideFormatSerializer{
+		Decorating: Decorating{Serializer: s},
+		format:     format,
+	}
+}
+
+type prefixSerializer struct {
+	Decorating
+
+	prefix  []byte
+	written bool
+}
+
+func (s *prefixSerializer) Append(w io.Writer, b []byte) error {
+	if !s.written {
+		s.written = true
+		if _, err := w.Write(s.prefix); err != nil {
+			return err
+		}
+	}
+
+	return s.Serializer.Append(w, b)
+}
+
+func (s *prefixSerializer) Reset() {
+	s.written = false
+	s.Serializer.Reset()
+}
+
+// WithPrefix decorates a Serializer so its output is preceded by prefix.
+// Nothing is written if no error is kept.
+func WithPrefix(s Serializer, prefix string) Serializer {
+	return &prefixSerializer{
+		Decorating: Decorating{Serializer: s},
+		prefix:     []byte(prefix),
+	}
+}
+
+type suffixSerializer struct {
+	Decorating
+
+	suffix  []byte
+	written bool
+}
+
+func (s *suffixSerializer) Append(w io.Writer, b []byte) error {
+	s.written = true
+	return s.Serializer.Append(w, b)
+}
+
+func (s *suffixSerializer) Reset() {
+	s.written = false
+	s.Serializer.Reset()
+}
+
+func (s *suffixSerializer) Flush(w io.Writer) error {
+	if err := flush(s.Serializer, w); err != nil {
+		return err
+	}
+
+	if !s.written {
+		return nil
+	}
+
+	_, err := w.Write(s.suffix)
+	return err
+}
+
+// WithSuffix decorates a Serializer so its output is followed by suffix.
+// Nothing is written if no error is kept.
+// The suffix is written by Flush, so it is only written by Printers, or callers calling Flush themselves.
+func WithSuffix(s Serializer, suffix string) Serializer {
+	return &suffixSerializer{
+		Decorating: Decorating{Serializer: s},
+		suffix:     []byte(suffix),
+	}
+}
+
+type reversingSerializer struct {
+	Decorating
+}
+
+func (s *reversingSerializer) order(bool) bool {
+	return s.Decorating.order(true)
+}
+
+// Reverse decorates a Serializer so Printers present it the errors in reverse order, innermost first,
+// as if the Printer was created WithReverseOrder.
+// Each error is formatted and appended as usual, so the Printer's truncation options apply as they would otherwise.
+// Its Append still follows the order it is called in: the frames of NewColonDetailedSerializer follow the message
+// of the error they wrap rather than their own, NewColonDetailedReverseSerializer keeps them with their own.
+// As with WithReverseOrder, the Printer's truncation marker comes first, and NewTreeSerializer can't be reversed.
+// Outside of Printers, it is the decorated Serializer.
+func Reverse(s Serializer) Serializer {
+	return &reversingSerializer{
+		Decorating: Decorating{Serializer: s},
+	}
+}
@@ -0,0 +1,157 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func TestCombinators(t *testing.T) {
+	err := xerrors.Wrap("start server",
+		xerrors.WithFields(
+			xerrors.Wrap("load config", errors.New("permission denied")),
+			xerrors.Field{Key: "path", Value: "foo"},
+		),
+	)
+
+	notFielder := func(err error) bool { return !xerrors.IsFielder(err) }
+	upper := func(err error, buf *bytes.Buffer) bool {
+		if xerrors.IsFrameError(err) {
+			return false
+		}
+		buf.WriteString(strings.ToUpper(err.Error()))
+		return true
+	}
+
+	scenarios := []struct {
+		name        string
+		factory     func() xerrors.Serializer
+		opts        []xerrors.PrinterOptionFunc
+		expectedOut string
+	}{
+		{
+			name: "filterKeep",
+			factory: func() xerrors.Serializer {
+				return xerrors.FilterKeep(xerrors.NewColonBasicSerializer(), notFielder)
+			},
+			expectedOut: "start server: load config: permission denied",
+		},
+		{
+			name: "overrideFormat",
+			factory: func() xerrors.Serializer {
+				return xerrors.OverrideFormat(xerrors.NewColonBasicSerializer(), upper)
+			},
+			expectedOut: "START SERVER: PATH=FOO: LOAD CONFIG: PERMISSION DENIED",
+		},
+		{
+			name: "prefixAndSuffix",
+			factory: func() xerrors.Serializer {
+				return xerrors.WithSuffix(xerrors.WithPrefix(xerrors.NewColonBasicSerializer(), "error: "), ".")
+			},
+			expectedOut: "error: start server: path=foo: load config: permission denied.",
+		},
+		{
+			name: "prefixAndSuffixNothingKept",
+			factory: func() xerrors.Serializer {
+				return xerrors.WithSuffix(xerrors.WithPrefix(xerrors.NewUserSerializer(xerrors.UserMessageAll), "["), "]")
+			},
+			expectedOut: "",
+		},
+		{
+			name: "reverse",
+			factory: func() xerrors.Serializer {
+				return xerrors.Reverse(xerrors.FilterKeep(xerrors.NewColonBasicSerializer(), notFielder))
+			},
+			expectedOut: "permission denied: load config: start server",
+		},
+		{
+			name: "reverseWithPrefixAndSuffix",
+			factory: func() xerrors.Serializer {
+				return xerrors.WithSuffix(xerrors.WithPrefix(xerrors.Reverse(xerrors.NewColonBasicSerializer()), "<"), ">")
+			},
+			expectedOut: "<permission denied: load config: path=foo: start server>",
+		},
+		{
+			name: "reverseMaxMessageLength",
+			factory: func() xerrors.Serializer {
+				return xerrors.Reverse(xerrors.NewColonBasicSerializer())
+			},
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxMessageLength(4)},
+			expectedOut: "perm...: load...: path...: star...",
+		},
+		{
+			name: "reverseMaxLength",
+			factory: func() xerrors.Serializer {
+				return xerrors.Reverse(xerrors.NewColonBasicSerializer())
+			},
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxLength(24)},
+			expectedOut: "permission denied: load co...",
+		},
+		{
+			name:    "reverseDetailed",
+			factory: func() xerrors.Serializer { return xerrors.Reverse(xerrors.NewColonDetailedSerializer()) },
+			// frames follow the message of the error they wrap
+			expectedOut: "permission denied(xerrors_test.TestCombinators:combinators_test.go:16): load config: " +
+				"path=foo(xerrors_test.TestCombinators:combinators_test.go:14): start server",
+		},
+		{
+			name: "decorating",
+			factory: func() xerrors.Serializer {
+				return &quotingSerializer{Decorating: xerrors.Decorating{Serializer: xerrors.WithSuffix(xerrors.NewColonDetailedSerializer(), ".")}}
+			},
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithFrameTrimmer(func(string, string) (string, string) { return "f", "file.go" })},
+			expectedOut: `"start server"(f:file.go:14): "path=foo": "load config"(f:file.go:16): "permission denied".`,
+		},
+		{
+			name: "reverseFormatters",
+			factory: func() xerrors.Serializer {
+				return xerrors.Reverse(xerrors.NewColonBasicSerializer())
+			},
+			opts: []xerrors.PrinterOptionFunc{xerrors.WithFormatters(func() *xerrors.FormatterRegistry {
+				r := xerrors.NewFormatterRegistry()
+				xerrors.RegisterFormatterTo(r, func(err xerrors.Fielder, buf *bytes.Buffer) {
+					buf.WriteString("<fields>")
+				})
+				return r
+			}())},
+			expectedOut: "permission denied: load config: <fields>: start server",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(scenario.factory, scenario.opts...)
+
+			// twice, to validate the pooled state is reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if err := printer.Write(&buf, err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
+
+// quotingSerializer is a decorator quoting the messages of errors other than FrameErrors.
+type quotingSerializer struct {
+	xerrors.Decorating
+}
+
+func (s *quotingSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	if xerrors.IsFrameError(err) {
+		return s.Decorating.CustomFormat(err, buf)
+	}
+	buf.WriteString(strconv.Quote(err.Error()))
+	return true
+}
@@ -8,44 +8,85 @@ func isNotFrameError(err error) bool {
 	return !IsFrameError(err)
 }
 
+// similarLayersSize is the number of layers Similar and Contains hold without allocating.
+const similarLayersSize = 16
+
 // Similar compares to errors and validates if they are logically identical.
 // This involves checking all error types and Error() outputs are identical, but ignores wrapped FrameErrors.
 // It is a replacement for reflect.DeepEqual(err1, err2) as the frame information will cause false negatives.
+// Errors wrapping multiple errors are walked depth-first, as per NonFrames, and must also wrap the same number of errors,
+// so trees are only similar if of the same structure.
 func Similar(err1, err2 error) bool {
-	for err1, err2 = Last(err1, isNotFrameError), Last(err2, isNotFrameError); err1 != nil && err2 != nil; err1, err2 = Last(Unwrap(err1), isNotFrameError), Last(Unwrap(err2), isNotFrameError) {
-		if t1, t2 := reflect.TypeOf(err1), reflect.TypeOf(err2); !reflect.DeepEqual(t1, t2) {
+	var buf [similarLayersSize]error
+	layers := buf[:0]
+	for err2 := range NonFrames(err2) {
+		layers = append(layers, err2)
+	}
+
+	i := 0
+	for err1 := range NonFrames(err1) {
+		if i == len(layers) || !similarLayer(err1, layers[i]) || similarArity(err1) != similarArity(layers[i]) {
 			return false
 		}
+		i++
+	}
 
-		if err1.Error() != err2.Error() {
-			return false
+	return i == len(layers)
+}
+
+// similarArity is the number of errors other than FrameErrors that err directly wraps, defining the structure of trees.
+func similarArity(err error) int {
+	mErr, ok := err.(multiWrapper)
+	if !ok {
+		if skipFrames(Unwrap(err)) == nil {
+			return 0
 		}
+		return 1
 	}
 
-	if err1 != nil || err2 != nil {
-		return false
+	n := 0
+	for _, child := range mErr.Unwrap() {
+		if skipFrames(child) != nil {
+			n++
+		}
 	}
+	return n
+}
 
-	return true
+// skipFrames returns err, or if it is a FrameError the first error it wraps that is not, nil if there is none.
+// It stops at MaxDepth, as walks do.
+func skipFrames(err error) error {
+	for i := 0; i < MaxDepth() && IsFrameError(err); i++ {
+		err = Unwrap(err)
+	}
+	return err
+}
+
+// similarLayer compares two errors as Similar does, but ignoring their wrapped errors.
+func similarLayer(err1, err2 error) bool {
+	return reflect.TypeOf(err1) == reflect.TypeOf(err2) && err1.Error() == err2.Error()
 }
 
 // Contains checks if err2 is logically contained within err1.
 // This involves checking all wrapped error types and Error() outputs in err2 appear in err1 in identical order.
 // It ignores wrapped FrameErrors altogether.
+// Errors wrapping multiple errors are flattened depth-first, as per NonFrames, unlike in Similar only their order matters.
 func Contains(err1, err2 error) bool {
-	for err2 = Last(err2, isNotFrameError); err2 != nil; err2 = Last(Unwrap(err2), isNotFrameError) {
-		t := reflect.TypeOf(err2)
-		msg := err2.Error()
-
-		err1 = Last(err1, func(err error) bool {
-			return reflect.DeepEqual(reflect.TypeOf(err), t) && err.Error() == msg
-		})
-		if err1 == nil {
-			return false
-		}
+	var buf [similarLayersSize]error
+	layers := buf[:0]
+	for err2 := range NonFrames(err2) {
+		layers = append(layers, err2)
+	}
 
-		err1 = Unwrap(err1)
+	i := 0
+	for err1 := range NonFrames(err1) {
+		if i == len(layers) {
+			break
+		}
+		if similarLayer(err1, layers[i]) {
+			i++
+		}
 	}
 
-	return true
+	return i == len(layers)
 }
@@ -19,6 +19,25 @@ func TestSimilar(t *testing.T) {
 		err2          error
 		expectedEqual bool
 	}{
+		{
+			name:          "equalMulti",
+			err1:          chainScenarioError(),
+			err2:          chainScenarioError(),
+			expectedEqual: true,
+		},
+		{
+			name: "flattenedMulti",
+			err1: multiError{[]error{xerrors.Wrap("left", xerrors.New("foo"), xerrors.OmitFrame()), xerrors.New("bar")}},
+			err2: multiError{[]error{xerrors.Wrap("left", nil, xerrors.OmitFrame()), xerrors.New("foo"), xerrors.New("bar")}},
+			// both are 'multi', 'left', 'foo', 'bar' once flattened, but 'foo' is wrapped by 'left' only in err1
+			expectedEqual: false,
+		},
+		{
+			name:          "framedMulti",
+			err1:          multiError{[]error{xerrors.Wrap("left", xerrors.New("foo")), xerrors.New("bar")}},
+			err2:          multiError{[]error{xerrors.Wrap("left", xerrors.New("foo"), xerrors.OmitFrame()), xerrors.New("bar")}},
+			expectedEqual: true,
+		},
 		{
 			name:          "equalNew",
 			err1:          xerrors.New("foo"),
@@ -101,6 +120,12 @@ func TestContains(t *testing.T) {
 		err2             error
 		expectedContains bool
 	}{
+		{
+			name:             "containedAcrossMulti",
+			err1:             chainScenarioError(),
+			err2:             xerrors.Wrap("left", xerrors.New("right"), xerrors.OmitFrame()),
+			expectedContains: true,
+		},
 		{
 			name:             "containedSentinel",
 			err1:             xerrors.Wrap("bar", xerrors.New("foo")),
@@ -149,3 +174,15 @@ func TestContains(t *testing.T) {
 		})
 	}
 }
+
+func TestSimilar_allocs(t *testing.T) {
+	err1 := xerrors.Wrap("bar", xerrors.Wrap("foo", xerrors.New("baz")))
+	err2 := xerrors.Wrap("bar", xerrors.Wrap("foo", xerrors.New("baz")))
+
+	if allocs := testing.AllocsPerRun(10, func() { xerrors.Similar(err1, err2) }); allocs != 0 {
+		t.Fatalf("expected Similar not to allocate, got %v allocations", allocs)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { xerrors.Contains(err1, err2) }); allocs != 0 {
+		t.Fatalf("expected Contains not to allocate, got %v allocations", allocs)
+	}
+}
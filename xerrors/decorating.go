@@ -0,0 +1,55 @@
+package xerrors
+
+import (
+	"io"
+)
+
+// Decorating is a helper struct to facilitate Serializers decorating other Serializers.
+// Embed it in a Serializer type and it provides all the methods of Serializer, FormatterUser, FrameTrimmerUser
+// and Flusher, forwarding them to the decorated Serializer.
+// The decorating type need only define the methods whose behaviour it changes.
+type Decorating struct {
+	Serializer
+}
+
+func (d Decorating) UseFormatters(r *FormatterRegistry) {
+	useFormatters(d.Serializer, r)
+}
+
+func (d Decorating) UseFrameTrimmer(t FrameTrimmer) {
+	useFrameTrimmer(d.Serializer, t)
+}
+
+func (d Decorating) Flush(w io.Writer) error {
+	return flush(d.Serializer, w)
+}
+
+func (d Decorating) useMaxDepth(depth int) {
+	useMaxDepth(d.Serializer, depth)
+}
+
+func (d Decorating) order(reverse bool) bool {
+	return serializerOrder(d.Serializer, reverse)
+}
+
+func (d Decorating) useRoot(err error) {
+	useRoot(d.Serializer, err)
+}
+
+func (d Decorating) truncateMessages(length int, ellipsis string) bool {
+	return truncateMessages(d.Serializer, length, ellipsis)
+}
+
+func (d Decorating) usesLayout() bool {
+	return usesLayout(d.Serializer)
+}
+
+func (d Decorating) useLayout(depths []int) {
+	useLayout(d.Serializer, depths)
+}
+
+var (
+	_ FormatterUser    = Decorating{}
+	_ FrameTrimmerUser = Decorating{}
+	_ Flusher          = Decorating{}
+)
@@ -0,0 +1,145 @@
+package xerrors
+
+// sameFrame reports if both frames are in the same function, and if matchLine also at the same line.
+func sameFrame(function string, line int, frameErr FrameError, matchLine bool) bool {
+	if frameErr == nil {
+		return false
+	}
+	otherFunction, _, otherLine := frameErr.FrameLocation()
+	return function == otherFunction && (!matchLine || line == otherLine)
+}
+
+// dedupedFrame is a frame of the chain being serialised, and if it has the same location as the next frame it wraps.
+type dedupedFrame struct {
+	err       FrameError
+	duplicate bool
+}
+
+type dedupeFramesSerializer struct {
+	Decorating
+
+	matchLine bool
+	// reverse is set if Printers present the errors innermost first, see order.
+	reverse  bool
+	maxDepth int
+
+	// Outermost first, frames holds all frames of the chain, laid out on the first Keep; next is that yet to be kept.
+	laidOut bool
+	frames  []dedupedFrame
+	next    int
+
+	// Innermost first, prev is the last frame presented, the next frame of those presented after it.
+	prev FrameError
+}
+
+func (s *dedupeFramesSerializer) Keep(err error) bool {
+	frameErr, ok := err.(FrameError)
+	if !ok {
+		return s.Serializer.Keep(err)
+	}
+
+	var duplicate bool
+	if s.reverse {
+		duplicate = s.isDuplicateOfPrev(frameErr)
+	} else {
+		if !s.laidOut {
+			s.layout(err)
+		}
+		duplicate = s.isDuplicate(frameErr)
+	}
+
+	return !duplicate && s.Serializer.Keep(err)
+}
+
+// layout walks err once, as Printers do, recording which of its frames are duplicates.
+// Each frame is compared with the previous one while the walk is within the latter's wrapped errors.
+func (s *dedupeFramesSerializer) layout(err error) {
+	s.laidOut = true
+
+	w := newWalker(true)
+	if s.maxDepth > 0 {
+		w = newWalkerDepth(true, s.maxDepth)
+	}
+
+	prev, prevDepth := -1, 0
+	w.walk(err, 0, func(depth int, err error) bool {
+		if prev != -1 && depth <= prevDepth {
+			prev = -1
+		}
+
+		frameErr, ok := err.(FrameError)
+		if !ok {
+			return true
+		}
+
+		if prev != -1 {
+			function, _, line := s.frames[prev].err.FrameLocation()
+			s.frames[prev].duplicate = sameFrame(function, line, frameErr, s.matchLine)
+		}
+
+		s.frames = append(s.frames, dedupedFrame{err: frameErr})
+		prev, prevDepth = len(s.frames)-1, depth
+		return true
+	})
+}
+
+// isDuplicate looks frameErr up in frames, past those already kept.
+// Frames not presented, such as those filtered out by other Serializers, are skipped over.
+func (s *dedupeFramesSerializer) isDuplicate(frameErr FrameError) bool {
+	for i := s.next; i < len(s.frames); i++ {
+		if identical(s.frames[i].err, frameErr) {
+			s.next = i + 1
+			return s.frames[i].duplicate
+		}
+	}
+	return false
+}
+
+// isDuplicateOfPrev compares frameErr with the previous frame presented.
+// Only if their locations match is it confirmed the previous frame is the next frameErr wraps, not that of another branch.
+func (s *dedupeFramesSerializer) isDuplicateOfPrev(frameErr FrameError) bool {
+	prev := s.prev
+	s.prev = frameErr
+
+	function, _, line := frameErr.FrameLocation()
+	if !sameFrame(function, line, prev, s.matchLine) {
+		return false
+	}
+	return identical(LastFrameError(frameErr.Unwrap()), prev)
+}
+
+func (s *dedupeFramesSerializer) order(reverse bool) bool {
+	s.reverse = s.Decorating.order(reverse)
+	return s.reverse
+}
+
+func (s *dedupeFramesSerializer) useMaxDepth(depth int) {
+	s.maxDepth = depth
+	s.Decorating.useMaxDepth(depth)
+}
+
+func (s *dedupeFramesSerializer) Reset() {
+	s.laidOut = false
+	clear(s.frames)
+	s.frames = s.frames[:0]
+	s.next = 0
+	s.prev = nil
+	s.Serializer.Reset()
+}
+
+// DedupeFrames decorates a Serializer so it omits frames in the same function as the next frame of the chain.
+// If matchLine, only frames also at the same line are omitted.
+// Of a run of such frames only the innermost is serialised, the closest to where the error originated.
+// The error chain itself is unchanged, see OmitDuplicateFrame to prevent such frames from being captured at all.
+func DedupeFrames(s Serializer, matchLine bool) Serializer {
+	return &dedupeFramesSerializer{
+		Decorating: Decorating{Serializer: s},
+		matchLine:  matchLine,
+	}
+}
+
+// NewColonDedupedSerializer provides NewColonDetailedSerializer decorated with DedupeFrames, matching functions only.
+// It prints a single frame for all the errors wrapped in the same function.
+func NewColonDedupedSerializer() Serializer {
+	return DedupeFrames(NewColonDetailedSerializer(), false)
+}
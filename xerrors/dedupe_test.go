@@ -0,0 +1,189 @@
+package xerrors_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func wrapTwice(opts ...xerrors.WrapOptionFunc) error {
+	err := xerrors.Wrap("inner", errors.New("cause"))
+	return xerrors.Wrap("outer", err, opts...)
+}
+
+func wrapTwiceSameLine(opts ...xerrors.WrapOptionFunc) error {
+	var err error = errors.New("cause")
+	for _, msg := range []string{"inner", "outer"} {
+		err = xerrors.Wrap(msg, err, opts...)
+	}
+	return err
+}
+
+// dedupeFrame is a static frame in /src/pkg/file.go, so outputs do not depend on the lines of this file.
+func dedupeFrame(function string, line int, err error) error {
+	return xerrors.NewStaticFrame(function, "/src/pkg/file.go", line, err)
+}
+
+func TestDedupeFrames(t *testing.T) {
+	sameFunction := xerrors.Wrap("outer", dedupeFrame("pkg.(*T).M", 20,
+		xerrors.Wrap("inner", dedupeFrame("pkg.(*T).M", 10, errors.New("cause")), xerrors.OmitFrame())), xerrors.OmitFrame())
+	sameLine := xerrors.Wrap("outer", dedupeFrame("pkg.F", 10,
+		xerrors.Wrap("inner", dedupeFrame("pkg.F", 10, errors.New("cause")), xerrors.OmitFrame())), xerrors.OmitFrame())
+
+	scenarios := []struct {
+		name        string
+		err         error
+		matchLine   bool
+		reverse     bool
+		expectedOut string
+	}{
+		{
+			name:        "sameFunction",
+			err:         sameFunction,
+			expectedOut: "outer: inner(pkg.(*T).M:file.go:10): cause",
+		},
+		{
+			name:        "sameFunctionMatchLine",
+			err:         sameFunction,
+			matchLine:   true,
+			expectedOut: "outer(pkg.(*T).M:file.go:20): inner(pkg.(*T).M:file.go:10): cause",
+		},
+		{
+			name:        "sameLine",
+			err:         sameLine,
+			matchLine:   true,
+			expectedOut: "outer: inner(pkg.F:file.go:10): cause",
+		},
+		{
+			name:        "run",
+			err:         dedupeFrame("pkg.(*T).M", 30, sameFunction),
+			expectedOut: "outer: inner(pkg.(*T).M:file.go:10): cause",
+		},
+		{
+			name:        "differentFunctions",
+			err:         dedupeFrame("pkg.G", 30, sameFunction),
+			expectedOut: "(pkg.G:file.go:30): outer: inner(pkg.(*T).M:file.go:10): cause",
+		},
+		{
+			name:        "noFrames",
+			err:         xerrors.Wrap("outer", errors.New("cause"), xerrors.OmitFrame()),
+			expectedOut: "outer: cause",
+		},
+		{
+			name:        "multi",
+			err:         dedupeFrame("pkg.F", 20, multiError{[]error{errors.New("left"), dedupeFrame("pkg.F", 10, errors.New("right"))}}),
+			expectedOut: "multi: left(pkg.F:file.go:10): right",
+		},
+		{
+			name:        "multiSiblings",
+			err:         multiError{[]error{dedupeFrame("pkg.F", 20, errors.New("left")), dedupeFrame("pkg.F", 10, errors.New("right"))}},
+			expectedOut: "multi(pkg.F:file.go:20): left(pkg.F:file.go:10): right",
+		},
+		{
+			name:        "reverse",
+			err:         dedupeFrame("pkg.(*T).M", 30, sameFunction),
+			reverse:     true,
+			expectedOut: "cause <- inner(pkg.(*T).M:file.go:10) <- outer",
+		},
+		{
+			name:        "reverseMultiSiblings",
+			err:         multiError{[]error{dedupeFrame("pkg.F", 20, errors.New("left")), dedupeFrame("pkg.F", 10, errors.New("right"))}},
+			reverse:     true,
+			expectedOut: "right <- left(pkg.F:file.go:10) <- multi(pkg.F:file.go:20)",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(func() xerrors.Serializer {
+				if scenario.reverse {
+					return xerrors.DedupeFrames(xerrors.NewColonDetailedReverseSerializer(), scenario.matchLine)
+				}
+				return xerrors.DedupeFrames(xerrors.NewColonDetailedSerializer(), scenario.matchLine)
+			})
+
+			// twice, to validate Reset
+			for range 2 {
+				buf := strings.Builder{}
+				if err := printer.Write(&buf, scenario.err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestNewColonDedupedSerializer(t *testing.T) {
+	err := wrapTwice()
+	printer := xerrors.NewPrinter(xerrors.NewColonDedupedSerializer)
+
+	buf := strings.Builder{}
+	if wErr := printer.Write(&buf, err); wErr != nil {
+		t.Fatalf("error serialising error: %s", wErr)
+	}
+
+	// the innermost frame is kept
+	_, _, line := xerrors.LastFrameError(xerrors.Unwrap(xerrors.Unwrap(err))).FrameLocation()
+	if expected := "outer: inner(xerrors_test.wrapTwice:dedupe_test.go:" + strconv.Itoa(line) + "): cause"; buf.String() != expected {
+		t.Fatalf("mismatched output, expected %q got %q", expected, buf.String())
+	}
+}
+
+func TestOmitDuplicateFrame(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		err            error
+		expectedFrames int
+	}{
+		{
+			name:           "sameFunction",
+			err:            wrapTwice(xerrors.OmitDuplicateFrame(false)),
+			expectedFrames: 1,
+		},
+		{
+			name:           "sameFunctionMatchLine",
+			err:            wrapTwice(xerrors.OmitDuplicateFrame(true)),
+			expectedFrames: 2,
+		},
+		{
+			name:           "sameLine",
+			err:            wrapTwiceSameLine(xerrors.OmitDuplicateFrame(true)),
+			expectedFrames: 1,
+		},
+		{
+			name:           "differentFunctions",
+			err:            xerrors.Wrap("outer", wrapTwice(), xerrors.OmitDuplicateFrame(false)),
+			expectedFrames: 3,
+		},
+		{
+			name:           "noWrappedFrame",
+			err:            xerrors.Wrap("outer", errors.New("cause"), xerrors.OmitDuplicateFrame(false)),
+			expectedFrames: 1,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if frames := countFrames(scenario.err); frames != scenario.expectedFrames {
+				t.Fatalf("mismatched frames, expected %d got %d: %s", scenario.expectedFrames, frames, xerrors.DetailString(scenario.err))
+			}
+		})
+	}
+
+	t.Run("location", func(t *testing.T) {
+		err := xerrors.Wrap("outer", errors.New("cause"), xerrors.OmitDuplicateFrame(false))
+		if function, expected := frameFunction(t, err), testPackage+".TestOmitDuplicateFrame.func2"; function != expected {
+			t.Fatalf("mismatched function, expected %q got %q", expected, function)
+		}
+	})
+}
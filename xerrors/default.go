@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"io"
 	"strconv"
-	"strings"
 	"sync"
 )
 
@@ -18,6 +17,9 @@ type colonSerializer struct {
 	firstEntry bool
 	keepFrames bool
 	isFrame    bool
+	formatters *FormatterRegistry
+	trimmer    FrameTrimmer
+	times      frameTimes
 }
 
 func (s *colonSerializer) Keep(err error) bool {
@@ -25,23 +27,36 @@ func (s *colonSerializer) Keep(err error) bool {
 }
 
 func (s *colonSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
-	if !s.keepFrames {
-		return false
-	}
-
 	frameErr, ok := err.(FrameError)
-	if !ok {
-		return false
+	if !ok || !s.keepFrames {
+		return s.formatters.Format(err, buf)
 	}
 
 	s.isFrame = true
 
-	function, file, line := frameErr.FrameLocation()
-	if i := strings.LastIndexByte(function, '/'); i != -1 {
-		function = function[i+1:]
+	if !s.formatters.Format(err, buf) {
+		formatShortFrame(frameErr, s.trimmer, &s.times, buf)
 	}
-	if i := strings.LastIndexByte(file, '/'); i != -1 {
-		file = file[i+1:]
+
+	return true
+}
+
+func (s *colonSerializer) UseFormatters(r *FormatterRegistry) {
+	s.formatters = r
+}
+
+func (s *colonSerializer) UseFrameTrimmer(t FrameTrimmer) {
+	s.trimmer = t
+}
+
+// formatShortFrame writes the frame location with the function and file trimmed, by default with TrimBase.
+// Timed frames are followed by their goroutine and time, relative to those of times.
+func formatShortFrame(frameErr FrameError, t FrameTrimmer, times *frameTimes, buf *bytes.Buffer) {
+	function, file, line := frameErr.FrameLocation()
+	function, file = trimFrame(t, function, file)
+
+	if _, ok := frameErr.(SpawnFrameError); ok {
+		buf.WriteString("spawned at ")
 	}
 
 	buf.WriteString(function)
@@ -50,25 +65,24 @@ func (s *colonSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
 	buf.WriteString(":")
 	buf.WriteString(strconv.Itoa(line))
 
-	return true
+	if timedErr, ok := frameErr.(TimedFrameError); ok {
+		times.format(timedErr, buf)
+	}
 }
 
 func (s *colonSerializer) Append(w io.Writer, msg []byte) error {
 	var err error
 
-	if s.firstEntry {
-		s.firstEntry = false
-	} else {
-		if s.isFrame {
-			if _, err = w.Write(frameOpen); err != nil {
-				return err
-			}
-		} else {
-			if _, err = w.Write(colonSeparator); err != nil {
-				return err
-			}
+	if s.isFrame {
+		if _, err = w.Write(frameOpen); err != nil {
+			return err
+		}
+	} else if !s.firstEntry {
+		if _, err = w.Write(colonSeparator); err != nil {
+			return err
 		}
 	}
+	s.firstEntry = false
 
 	_, err = w.Write(msg)
 
@@ -85,6 +99,7 @@ func (s *colonSerializer) Append(w io.Writer, msg []byte) error {
 func (s *colonSerializer) Reset() {
 	s.firstEntry = true
 	s.isFrame = false
+	s.times.reset()
 }
 
 func newColonSerializer(keepFrames bool) Serializer {
@@ -92,17 +107,20 @@ func newColonSerializer(keepFrames bool) Serializer {
 		firstEntry: true,
 		keepFrames: keepFrames,
 		isFrame:    false,
+		formatters: defaultFormatters,
 	}
 }
 
 // NewColonBasicSerializer provides a formatter that appends messages with ': ' and omits frames.
+// Errors with a formatter in DefaultFormatters (or that of the Printer, see WithFormatters) are formatted with it.
 // It is the serializer used by the %s representation of errors.
 func NewColonBasicSerializer() Serializer {
 	return newColonSerializer(false)
 }
 
 // NewColonDetailedSerializer provides a formatter that appends messages with ': '.
-// Frames are printed in a shortened mode between brackets.
+// Frames are printed in a shortened mode between brackets, see WithFrameTrimmer to configure it.
+// Errors with a formatter in DefaultFormatters (or that of the Printer, see WithFormatters) are formatted with it.
 // It is the serializer used by the %v representation of errors.
 func NewColonDetailedSerializer() Serializer {
 	return newColonSerializer(true)
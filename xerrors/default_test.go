@@ -34,6 +34,12 @@ func encodeScenarios() []encodeScenario {
 			expectedBasicOut:  "wrapping_msg_2: wrapping_msg_1: cause_msg",
 			expectedDetailOut: "wrapping_msg_2(xerrors_test.encodeScenarios:default_test.go:30): wrapping_msg_1(xerrors_test.encodeScenarios:default_test.go:32): cause_msg",
 		},
+		{
+			name:              "outermostFrame",
+			err:               xerrors.NewStaticFrame("pkg.Function", "/src/pkg/file.go", 12, xerrors.New("cause_msg")),
+			expectedBasicOut:  "cause_msg",
+			expectedDetailOut: "(pkg.Function:file.go:12): cause_msg",
+		},
 	}
 }
 
@@ -11,9 +11,16 @@
 // A default Serializer implementation is provided, serializing errors in the popular  "%s: %s: %s: ..." format.
 //
 // The Printer uses Serializer to turn an error into a string, including all it's wrapped inner errors.
+// Its options may truncate long messages and outputs, whatever the Serializer.
+// RegisterFormatter customises how the default serializers print errors of a given type, WithFormatters does so for a Printer only.
+// FilterKeep, OverrideFormat, WithPrefix, WithSuffix and Reverse adapt existing serializers by composition,
+// and Decorating helps writing further such decorators.
 //
 // The String and DetailString methods give easy access to the default Serializer.
 // They are meant to be used when printing errors in "%s" and "%v" format.
+// ReverseString and ReverseDetailString print the root cause first, any Printer may do so WithReverseOrder.
+// TreeString is the multi-line form of DetailString, meant for command line tools and debugging.
+// NewLogfmtSerializer provides a serializer for logfmt logs, with Fielder and MultiKeyValuer errors expanded into native pairs.
 // String(err) is the new representation of what was previously written as err.Error().
 //
 // Method New is preserved as a default string error initialisation, without error wrapping.
@@ -25,8 +32,33 @@
 // Wrapping and NewWrapping provide an easy way for custom errors to implement Wrapper and have frame information.
 //
 // Method Last is used to navigate the wrapped error chain and fetch any error of interest within it.
+// All and Layers iterate over the wrapped error chain, or tree for errors wrapping multiple errors.
+// Revealed iterates over the errors as Printers present them, seeing through Opaque.
+// Walks stop at cycles and beyond MaxDepth, Printers write a truncation marker in such cases.
 //
 // Method Similar is an alternative to reflect.DeepEqual for error comparison which omits frames from the comparison.
 //
 // Method Contains validates if one error is contained within another, including all wrapped errors but omitting frames.
+//
+// Sensitive and Redactable errors have their messages hidden by serializers decorated with NewRedactingSerializer.
+// RedactedString and RedactedDetailString are the redacting forms of String and DetailString.
+//
+// UserMessage errors carry messages safe to show to end users, UserString serialises only those.
+//
+// Fielder errors carry typed structured fields, WithFields adds them to any error and Fields collects them.
+// Struct errors may instead tag their fields with `xerr:"name"`, see TaggedFields.
+// SlogValue represents errors with their fields for log/slog, redacted.
+//
+// NewCollapsingSerializer serialises consecutive similar errors, such as those produced in retry loops, only once.
+//
+// Method Opaque hides an error's wrapped chain from inspection, while still serialising it in full.
+//
+// Methods Map, Filter and StripFrames rebuild error chains, relying on errors implementing Rewrapper or embedding Wrapping.
+// NewStaticFrame produces frames that survive serialization, StaticFrames converts those of a chain to them.
+// SetFramePolicy and SetPackageFramePolicy control when frames are captured: always, never, sampled or on the first wrap only.
+// Helper and SetHelperPackage mark wrapping utilities, so frames report their callers instead.
+// DedupeFrames and OmitDuplicateFrame avoid repeated frames of errors wrapped more than once in the same function.
+// WithFrameTrimmer configures how Printers present frame locations: TrimBase, TrimNone, TrimModule or TrimGoPaths.
+// RecordTime records the time and goroutine of a wrapping in its frame, detailed output shows how long errors took to propagate.
+// Go and SpawnSite attach the location goroutines were launched from to the errors coming out of them.
 package xerrors
@@ -0,0 +1,10 @@
+package xerrors
+
+// SaveDefaultFormatters snapshots DefaultFormatters, returning a function restoring it.
+// It is for tests registering formatters globally, to be passed to t.Cleanup.
+func SaveDefaultFormatters() (restore func()) {
+	saved := defaultFormatters.snapshot.Load()
+	return func() {
+		defaultFormatters.snapshot.Store(saved)
+	}
+}
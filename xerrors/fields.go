@@ -0,0 +1,195 @@
+package xerrors
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Field is a typed key-value pair carried by an error.
+// Values should be of basic types (string, bool, numeric, time.Duration, time.Time) or values serializers can
+// represent natively, such as JSON marshalable types.
+type Field struct {
+	Key   string
+	Value any
+	// Sensitive fields have their Value replaced by RedactedPlaceholder by redacting serializers, see RedactFields.
+	// All other serializers, and Fields, expose it as is.
+	Sensitive bool
+}
+
+// hasSensitive reports if any of the fields is Sensitive.
+func hasSensitive(fields []Field) bool {
+	for _, field := range fields {
+		if field.Sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactFields returns the fields with the Value of Sensitive ones replaced by RedactedPlaceholder.
+// The fields are returned as is if none are Sensitive, otherwise they are copied.
+func RedactFields(fields []Field) []Field {
+	if !hasSensitive(fields) {
+		return fields
+	}
+
+	redacted := make([]Field, len(fields))
+	copy(redacted, fields)
+	for i := range redacted {
+		if redacted[i].Sensitive {
+			redacted[i].Value = RedactedPlaceholder
+		}
+	}
+	return redacted
+}
+
+// Fielder is an error carrying structured fields.
+// Structured serializers, such as that of NewLogfmtSerializer, serialise the fields with their native types.
+type Fielder interface {
+	Wrapper
+	Fields() []Field
+}
+
+// IsFielder is a helper for type casting to Fielder
+func IsFielder(err error) bool {
+	_, ok := err.(Fielder)
+	return ok
+}
+
+// WithFields wraps an error with structured fields.
+// The wrapping error's message is the fields in logfmt form, 'key=value key2=value2'.
+// Being an annotation rather than a failure in itself, it never produces a FrameError.
+func WithFields(err error, fields ...Field) error {
+	return &fieldsError{
+		fields:   fields,
+		Wrapping: Wrapping{err: err},
+	}
+}
+
+type fieldsError struct {
+	fields []Field
+	Wrapping
+}
+
+func (err *fieldsError) Error() string {
+	return fieldsMessage(err.fields)
+}
+
+// fieldsMessage returns the fields in logfmt form, the message of the errors of WithFields.
+func fieldsMessage(fields []Field) string {
+	buf := bytes.Buffer{}
+	for i, field := range fields {
+		if i != 0 {
+			buf.WriteByte(' ')
+		}
+		writeLogfmtKey(&buf, field.Key)
+		buf.WriteByte('=')
+		writeLogfmtFieldValue(&buf, field.Value)
+	}
+	return buf.String()
+}
+
+func (err *fieldsError) Fields() []Field {
+	return err.fields
+}
+
+var _ Fielder = (*fieldsError)(nil)
+
+// FieldConflictPolicy defines how Fields resolves fields with the same key in different errors of the chain.
+type FieldConflictPolicy uint8
+
+const (
+	// FieldsOutermost keeps the value of the first field with a given key in the chain.
+	FieldsOutermost FieldConflictPolicy = iota
+	// FieldsInnermost keeps the value of the last field with a given key in the chain.
+	FieldsInnermost
+	// FieldsAll keeps all fields, repeating keys as required.
+	FieldsAll
+)
+
+// Fields collects the fields of all errors in the chain, outermost first, as per FieldsOutermost.
+// These are the fields of Fielder errors, or TaggedFields for other errors.
+func Fields(err error) []Field {
+	return FieldsWithPolicy(err, FieldsOutermost)
+}
+
+// FieldsWithPolicy collects the fields of all errors in the chain, outermost first, as Fields does.
+// Fields with repeated keys are resolved as per the policy, in the position of the first field with that key.
+func FieldsWithPolicy(err error, policy FieldConflictPolicy) []Field {
+	var fields []Field
+	var index map[string]int
+
+	for err := range All(err) {
+		for _, field := range fieldsOf(err) {
+			if policy == FieldsAll {
+				fields = append(fields, field)
+				continue
+			}
+
+			if index == nil {
+				index = make(map[string]int)
+			}
+
+			i, ok := index[field.Key]
+			switch {
+			case !ok:
+				index[field.Key] = len(fields)
+				fields = append(fields, field)
+			case policy == FieldsInnermost:
+				fields[i] = field
+			}
+		}
+	}
+
+	return fields
+}
+
+// writeLogfmtFieldValue writes the field value in logfmt form, quoting and escaping it if required.
+func writeLogfmtFieldValue(buf *bytes.Buffer, value any) {
+	var aux [64]byte
+
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		writeLogfmtValue(buf, v)
+	case bool:
+		buf.Write(strconv.AppendBool(aux[:0], v))
+	case int:
+		buf.Write(strconv.AppendInt(aux[:0], int64(v), 10))
+	case int8:
+		buf.Write(strconv.AppendInt(aux[:0], int64(v), 10))
+	case int16:
+		buf.Write(strconv.AppendInt(aux[:0], int64(v), 10))
+	case int32:
+		buf.Write(strconv.AppendInt(aux[:0], int64(v), 10))
+	case int64:
+		buf.Write(strconv.AppendInt(aux[:0], v, 10))
+	case uint:
+		buf.Write(strconv.AppendUint(aux[:0], uint64(v), 10))
+	case uint8:
+		buf.Write(strconv.AppendUint(aux[:0], uint64(v), 10))
+	case uint16:
+		buf.Write(strconv.AppendUint(aux[:0], uint64(v), 10))
+	case uint32:
+		buf.Write(strconv.AppendUint(aux[:0], uint64(v), 10))
+	case uint64:
+		buf.Write(strconv.AppendUint(aux[:0], v, 10))
+	case float32:
+		buf.Write(strconv.AppendFloat(aux[:0], float64(v), 'g', -1, 32))
+	case float64:
+		buf.Write(strconv.AppendFloat(aux[:0], v, 'g', -1, 64))
+	case time.Duration:
+		writeLogfmtValue(buf, v.String())
+	case time.Time:
+		buf.Write(v.AppendFormat(aux[:0], time.RFC3339Nano))
+	case error:
+		writeLogfmtValue(buf, String(v))
+	case fmt.Stringer:
+		writeLogfmtValue(buf, v.String())
+	default:
+		writeLogfmtValue(buf, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,183 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func fieldsScenarioError() error {
+	return xerrors.WithFields(
+		xerrors.Wrap(
+			"wrapper",
+			xerrors.WithFields(
+				xerrors.New("msg"),
+				xerrors.Field{Key: "user", Value: 2},
+				xerrors.Field{Key: "timeout", Value: time.Second},
+			),
+			xerrors.OmitFrame(),
+		),
+		xerrors.Field{Key: "user", Value: 1},
+		xerrors.Field{Key: "path", Value: "/my path"},
+	)
+}
+
+func TestFieldsWithPolicy(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		policy         xerrors.FieldConflictPolicy
+		expectedFields []xerrors.Field
+	}{
+		{
+			name:   "outermost",
+			policy: xerrors.FieldsOutermost,
+			expectedFields: []xerrors.Field{
+				{Key: "user", Value: 1},
+				{Key: "path", Value: "/my path"},
+				{Key: "timeout", Value: time.Second},
+			},
+		},
+		{
+			name:   "innermost",
+			policy: xerrors.FieldsInnermost,
+			expectedFields: []xerrors.Field{
+				{Key: "user", Value: 2},
+				{Key: "path", Value: "/my path"},
+				{Key: "timeout", Value: time.Second},
+			},
+		},
+		{
+			name:   "all",
+			policy: xerrors.FieldsAll,
+			expectedFields: []xerrors.Field{
+				{Key: "user", Value: 1},
+				{Key: "path", Value: "/my path"},
+				{Key: "user", Value: 2},
+				{Key: "timeout", Value: time.Second},
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if fields := xerrors.FieldsWithPolicy(fieldsScenarioError(), scenario.policy); !reflect.DeepEqual(fields, scenario.expectedFields) {
+				t.Fatalf("mismatched fields, expected %v got %v", scenario.expectedFields, fields)
+			}
+		})
+	}
+
+	if fields := xerrors.Fields(xerrors.New("msg")); fields != nil {
+		t.Fatalf("expected no fields, got %v", fields)
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	err := fieldsScenarioError()
+
+	if out, expectedOut := xerrors.String(err), `user=1 path="/my path": wrapper: user=2 timeout=1s: msg`; out != expectedOut {
+		t.Fatalf("mismatched String output, expected %q got %q", expectedOut, out)
+	}
+
+	printer := xerrors.NewPrinter(func() xerrors.Serializer { return xerrors.NewLogfmtSerializer() })
+	buf := bytes.Buffer{}
+	if err := printer.Write(&buf, err); err != nil {
+		t.Fatalf("error serialising error: %s", err)
+	}
+
+	expectedOut := `err.0.user=1 err.0.path="/my path" err.0.type=*xerrors.fieldsError ` +
+		`err.1.msg=wrapper err.1.type=*xerrors.wrappingError ` +
+		`err.2.user=2 err.2.timeout=1s err.2.type=*xerrors.fieldsError ` +
+		`err.3.msg=msg err.3.type=xerrors.baseError`
+	if buf.String() != expectedOut {
+		t.Fatalf("mismatched logfmt output, expected %q got %q", expectedOut, buf.String())
+	}
+}
+
+func TestWithFields_sensitive(t *testing.T) {
+	err := xerrors.WithFields(xerrors.New("msg"), xerrors.Field{Key: "user", Value: 1}, xerrors.Field{Key: "token", Value: "secret", Sensitive: true})
+
+	if out, expectedOut := xerrors.String(err), `user=1 token=secret: msg`; out != expectedOut {
+		t.Fatalf("mismatched String output, expected %q got %q", expectedOut, out)
+	}
+
+	if out, expectedOut := xerrors.RedactedString(err), `user=1 token=[REDACTED]: msg`; out != expectedOut {
+		t.Fatalf("mismatched RedactedString output, expected %q got %q", expectedOut, out)
+	}
+
+	expectedFields := []xerrors.Field{{Key: "user", Value: 1}, {Key: "token", Value: xerrors.RedactedPlaceholder, Sensitive: true}}
+	if fields := xerrors.RedactFields(xerrors.Fields(err)); !reflect.DeepEqual(fields, expectedFields) {
+		t.Fatalf("mismatched redacted fields, expected %v got %v", expectedFields, fields)
+	}
+	if fields := xerrors.Fields(err); fields[1].Value != "secret" {
+		t.Fatalf("expected RedactFields not to modify its input, got %v", fields)
+	}
+}
+
+func TestSlogValue(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+
+	logger.Info("failed", xerrors.SlogAttr("err", fieldsScenarioError()))
+
+	expectedOut := `{"level":"INFO","msg":"failed","err":{"msg":"user=1 path=\"/my path\": wrapper: user=2 timeout=1s: msg",` +
+		`"user":1,"path":"/my path","timeout":1000000000}}` + "\n"
+	if buf.String() != expectedOut {
+		t.Fatalf("mismatched output, expected %q got %q", expectedOut, buf.String())
+	}
+}
+
+func TestFieldsWithPolicy_sensitive(t *testing.T) {
+	err := xerrors.WithFields(
+		xerrors.WithFields(xerrors.New("msg"), xerrors.Field{Key: "email", Value: "a@b.c", Sensitive: true}),
+		xerrors.Field{Key: "email", Value: "unknown"},
+	)
+
+	expectedFields := []xerrors.Field{{Key: "email", Value: "a@b.c", Sensitive: true}}
+	fields := xerrors.FieldsWithPolicy(err, xerrors.FieldsInnermost)
+	if !reflect.DeepEqual(fields, expectedFields) {
+		t.Fatalf("mismatched fields, expected %v got %v", expectedFields, fields)
+	}
+
+	expectedFields = []xerrors.Field{{Key: "email", Value: xerrors.RedactedPlaceholder, Sensitive: true}}
+	if fields := xerrors.RedactFields(fields); !reflect.DeepEqual(fields, expectedFields) {
+		t.Fatalf("mismatched redacted fields, expected %v got %v", expectedFields, fields)
+	}
+}
+
+func TestSlogValue_sensitive(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+
+	err := xerrors.WithFields(
+		xerrors.WrapSensitive("token abc", xerrors.New("msg"), xerrors.OmitFrame()),
+		xerrors.Field{Key: "user", Value: 1},
+		xerrors.Field{Key: "email", Value: "a@b.c", Sensitive: true},
+	)
+	logger.Info("failed", xerrors.SlogAttr("err", err))
+
+	expectedOut := `{"level":"INFO","msg":"failed","err":{"msg":"user=1 email=[REDACTED]: [REDACTED]: msg",` +
+		`"user":1,"email":"[REDACTED]"}}` + "\n"
+	if buf.String() != expectedOut {
+		t.Fatalf("mismatched output, expected %q got %q", expectedOut, buf.String())
+	}
+}
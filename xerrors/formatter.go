@@ -0,0 +1,129 @@
+package xerrors
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+type formatterFunc = func(error, *bytes.Buffer)
+
+type interfaceFormatter struct {
+	t reflect.Type
+	f formatterFunc
+}
+
+// formatters is an immutable snapshot of the contents of a FormatterRegistry.
+type formatters struct {
+	types      map[reflect.Type]formatterFunc
+	interfaces []interfaceFormatter
+}
+
+// FormatterRegistry holds custom formatting functions for error types.
+// It is consulted by the colon serializers, instead of the error's Error method, and may be so by any Serializer.
+// It is safe for concurrent use, and lookups are lock free.
+type FormatterRegistry struct {
+	mux      sync.Mutex
+	snapshot atomic.Pointer[formatters]
+	parent   *FormatterRegistry
+}
+
+var defaultFormatters = &FormatterRegistry{}
+
+// DefaultFormatters returns the global registry, to which RegisterFormatter adds formatters.
+func DefaultFormatters() *FormatterRegistry {
+	return defaultFormatters
+}
+
+// NewFormatterRegistry initialises a registry, for use WithFormatters in a Printer.
+// Errors without a formatter in it fall back to those of DefaultFormatters.
+func NewFormatterRegistry() *FormatterRegistry {
+	return &FormatterRegistry{parent: defaultFormatters}
+}
+
+// RegisterFormatter adds a formatter for errors of type T to the global registry, replacing any previous one.
+// See RegisterFormatterTo.
+func RegisterFormatter[T error](f func(T, *bytes.Buffer)) {
+	RegisterFormatterTo(defaultFormatters, f)
+}
+
+// RegisterFormatterTo adds a formatter for errors of type T to the registry, replacing any previous one.
+// If T is a concrete type, the formatter is used for errors of exactly that type.
+// If T is an interface, it is used for errors implementing it, unless they have a concrete type formatter.
+// Interface formatters are consulted in the order they were registered.
+func RegisterFormatterTo[T error](r *FormatterRegistry, f func(T, *bytes.Buffer)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	ff := func(err error, buf *bytes.Buffer) {
+		f(err.(T), buf)
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	next := &formatters{types: make(map[reflect.Type]formatterFunc)}
+	if current := r.snapshot.Load(); current != nil {
+		for k, v := range current.types {
+			next.types[k] = v
+		}
+		next.interfaces = append(next.interfaces, current.interfaces...)
+	}
+
+	if t.Kind() != reflect.Interface {
+		next.types[t] = ff
+	} else {
+		replaced := false
+		for i := range next.interfaces {
+			if next.interfaces[i].t == t {
+				next.interfaces[i].f = ff
+				replaced = true
+			}
+		}
+		if !replaced {
+			next.interfaces = append(next.interfaces, interfaceFormatter{t: t, f: ff})
+		}
+	}
+
+	r.snapshot.Store(next)
+}
+
+// Format writes err to the buffer with its registered formatter, if any.
+// It returns false, writing nothing, if there is none, in the registry or the registry it falls back to.
+// Its semantics are those of Serializer's CustomFormat, so serializers may use it there.
+func (r *FormatterRegistry) Format(err error, buf *bytes.Buffer) bool {
+	for ; r != nil; r = r.parent {
+		current := r.snapshot.Load()
+		if current == nil {
+			continue
+		}
+
+		t := reflect.TypeOf(err)
+		if f, ok := current.types[t]; ok {
+			f(err, buf)
+			return true
+		}
+
+		for _, iFormatter := range current.interfaces {
+			if t.Implements(iFormatter.t) {
+				iFormatter.f(err, buf)
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// FormatterUser is implemented by Serializers that consult a FormatterRegistry, DefaultFormatters by default.
+// Printers created WithFormatters provide their registry to the Serializers through it.
+// Serializers decorating other Serializers should forward it to them, as Decorating does.
+type FormatterUser interface {
+	UseFormatters(*FormatterRegistry)
+}
+
+// useFormatters forwards the registry to the Serializer, if it is a FormatterUser.
+func useFormatters(s Serializer, r *FormatterRegistry) {
+	if fu, ok := s.(FormatterUser); ok {
+		fu.UseFormatters(r)
+	}
+}
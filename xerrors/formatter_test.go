@@ -0,0 +1,146 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+type codedError interface {
+	error
+	Code() int
+}
+
+type formatterCodedError struct {
+	code int
+	xerrors.Wrapping
+}
+
+func (err *formatterCodedError) Error() string { return "coded" }
+
+func (err *formatterCodedError) Code() int { return err.code }
+
+// globalFormatterError is only registered globally, by TestRegisterFormatter, for the duration of the test.
+type globalFormatterError struct {
+	xerrors.Wrapping
+}
+
+func (*globalFormatterError) Error() string { return "global" }
+
+func TestFormatterRegistry(t *testing.T) {
+	registry := xerrors.NewFormatterRegistry()
+	xerrors.RegisterFormatterTo(registry, func(err *os.PathError, buf *bytes.Buffer) {
+		buf.WriteString(err.Op)
+		buf.WriteByte(' ')
+		buf.WriteString(filepath.Base(err.Path))
+	})
+	xerrors.RegisterFormatterTo(registry, func(err codedError, buf *bytes.Buffer) {
+		buf.WriteString("code ")
+		buf.WriteString(strconv.Itoa(err.Code()))
+	})
+
+	pathErr := &os.PathError{Op: "open", Path: "/tmp/dir/file.txt", Err: errors.New("no such file")}
+
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut string
+	}{
+		{
+			name:        "concreteType",
+			err:         xerrors.Wrap("config", pathErr, xerrors.OmitFrame()),
+			expectedOut: "config: open file.txt: no such file",
+		},
+		{
+			name:        "interface",
+			err:         xerrors.Wrap("outer", &formatterCodedError{code: 42}, xerrors.OmitFrame()),
+			expectedOut: "outer: code 42",
+		},
+		{
+			name:        "unregistered",
+			err:         xerrors.Wrap("outer", errors.New("inner"), xerrors.OmitFrame()),
+			expectedOut: "outer: inner",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(xerrors.NewColonBasicSerializer, xerrors.WithFormatters(registry))
+
+			buf := bytes.Buffer{}
+			if err := printer.Write(&buf, scenario.err); err != nil {
+				t.Fatalf("error serialising error: %s", err)
+			}
+
+			if buf.String() != scenario.expectedOut {
+				t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+			}
+		})
+	}
+
+	t.Run("scopedToPrinter", func(t *testing.T) {
+		err := xerrors.Wrap("config", pathErr, xerrors.OmitFrame())
+		if expected, got := "config: open /tmp/dir/file.txt: no such file: no such file", xerrors.String(err); got != expected {
+			t.Fatalf("mismatched output, expected %q got %q", expected, got)
+		}
+	})
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	err := xerrors.Wrap("outer", &globalFormatterError{}, xerrors.OmitFrame())
+
+	t.Run("registered", func(t *testing.T) {
+		t.Cleanup(xerrors.SaveDefaultFormatters())
+
+		xerrors.RegisterFormatter(func(err *globalFormatterError, buf *bytes.Buffer) {
+			buf.WriteString("custom global")
+		})
+
+		if expected, got := "outer: custom global", xerrors.String(err); got != expected {
+			t.Fatalf("mismatched String output, expected %q got %q", expected, got)
+		}
+
+		// printers with their own registry fall back to the global one
+		printer := xerrors.NewPrinter(xerrors.NewColonBasicSerializer, xerrors.WithFormatters(xerrors.NewFormatterRegistry()))
+		buf := bytes.Buffer{}
+		if err := printer.Write(&buf, err); err != nil {
+			t.Fatalf("error serialising error: %s", err)
+		}
+		if expected := "outer: custom global"; buf.String() != expected {
+			t.Fatalf("mismatched Printer output, expected %q got %q", expected, buf.String())
+		}
+	})
+
+	if expected, got := "outer: global", xerrors.String(err); got != expected {
+		t.Fatalf("expected the global registry to be restored, expected %q got %q", expected, got)
+	}
+}
+
+func TestFormatterRegistry_frame(t *testing.T) {
+	registry := xerrors.NewFormatterRegistry()
+	xerrors.RegisterFormatterTo(registry, func(err xerrors.FrameError, buf *bytes.Buffer) {
+		_, _, line := err.FrameLocation()
+		buf.WriteString("line ")
+		buf.WriteString(strconv.Itoa(line))
+	})
+
+	// beware, the line of this call is part of the expected output
+	err := xerrors.Wrap("msg", errors.New("cause"))
+
+	printer := xerrors.NewPrinter(xerrors.NewColonDetailedSerializer, xerrors.WithFormatters(registry))
+	buf := bytes.Buffer{}
+	if err := printer.Write(&buf, err); err != nil {
+		t.Fatalf("error serialising error: %s", err)
+	}
+
+	if expected := "msg(line 135): cause"; buf.String() != expected {
+		t.Fatalf("mismatched output, expected %q got %q", expected, buf.String())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"runtime"
 	"strconv"
+	"sync"
 )
 
 // caller returns a Frame that describes a frame on the caller's stack.
@@ -50,6 +51,8 @@ func formatFrames(function, file string, line int, buf *bytes.Buffer) {
 }
 
 // FrameError is an error with part of a call stack.
+// The Error of those captured by this package, by Wrap and the like, is their location with TrimGoPaths applied.
+// Serializers present FrameLocation with their own trimming instead, see WithFrameTrimmer.
 type FrameError interface {
 	Wrapper
 	FrameLocation() (string, string, int)
@@ -78,20 +81,74 @@ type frameError struct {
 	Wrapping
 }
 
-func (err *frameError) Error() string {
-	function, file, line := err.FrameLocation()
+// frameErrorTrimmer is the FrameTrimmer of the Error of frames, initialised on first use.
+var frameErrorTrimmer = sync.OnceValue(TrimGoPaths)
+
+// frameErrorString is the Error of frames, captured or static alike, with files trimmed by frameErrorTrimmer.
+func frameErrorString(function, file string, line int) string {
+	function, file = frameErrorTrimmer()(function, file)
 	buf := bytes.Buffer{}
 	formatFrames(function, file, line, &buf)
 	return buf.String()
 }
 
+func (err *frameError) Error() string {
+	return frameErrorString(err.FrameLocation())
+}
+
 func (err *frameError) FrameLocation() (string, string, int) {
 	return location(err.frames)
 }
 
-func newFrameError(skip uint8, err error) error {
-	return &frameError{
-		frames:   caller(skip + 1),
+// NewStaticFrame produces a FrameError for the given location, wrapping err.
+// Unlike the frames produced by Wrap, which hold program counters meaningful only to the running process,
+// it holds the location itself, so it can be persisted or sent to other processes.
+// It is intended for decoders of serialised errors, and for tests.
+func NewStaticFrame(function, file string, line int, err error) error {
+	return &staticFrameError{
+		function: function,
+		file:     file,
+		line:     line,
 		Wrapping: Wrapping{err: err},
 	}
 }
+
+type staticFrameError struct {
+	function, file string
+	line           int
+	Wrapping
+}
+
+func (err *staticFrameError) Error() string {
+	return frameErrorString(err.function, err.file, err.line)
+}
+
+func (err *staticFrameError) FrameLocation() (string, string, int) {
+	return err.function, err.file, err.line
+}
+
+var _ FrameError = (*staticFrameError)(nil)
+
+// StaticFrames rebuilds the chain of err with every FrameError replaced by a static one, see NewStaticFrame.
+// The output has the same serialised form, but no longer depends on the running process.
+// TimedFrameErrors and SpawnFrameErrors remain so, see NewStaticTimedFrame and NewStaticSpawnFrame.
+// It is a special case of Map, with the same rewrapping behaviour.
+func StaticFrames(err error) error {
+	return Map(err, func(err error) error {
+		switch frameErr := err.(type) {
+		case *staticFrameError, *staticTimedFrameError, *staticSpawnFrameError:
+			return err
+		case TimedFrameError:
+			function, file, line := frameErr.FrameLocation()
+			return NewStaticTimedFrame(function, file, line, frameErr.FrameTime(), frameErr.FrameGoroutine(), nil)
+		case SpawnFrameError:
+			function, file, line := frameErr.FrameLocation()
+			return NewStaticSpawnFrame(function, file, line, nil)
+		case FrameError:
+			function, file, line := frameErr.FrameLocation()
+			return NewStaticFrame(function, file, line, nil)
+		default:
+			return err
+		}
+	})
+}
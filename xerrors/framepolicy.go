@@ -0,0 +1,164 @@
+package xerrors
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// FramePolicy decides if NewWrapping, Wrap and the other wrapping constructors capture a frame.
+// It never overrides OmitFrame, which always omits it.
+// Besides those of this package, users may implement their own.
+type FramePolicy interface {
+	// CaptureFrame reports if a frame is captured for a wrapping of err.
+	// It is called on every wrapping subject to the policy, possibly concurrently, so it should be cheap.
+	CaptureFrame(err error) bool
+}
+
+type framesAlways struct{}
+
+func (framesAlways) CaptureFrame(error) bool { return true }
+
+type framesNever struct{}
+
+func (framesNever) CaptureFrame(error) bool { return false }
+
+type framesSampled struct {
+	n     uint64
+	count atomic.Uint64
+}
+
+func (p *framesSampled) CaptureFrame(error) bool {
+	return (p.count.Add(1)-1)%p.n == 0
+}
+
+type framesFirstWrap struct{}
+
+func (framesFirstWrap) CaptureFrame(err error) bool {
+	return !chainFramed(err) && LastFrameError(err) == nil
+}
+
+// FramesAlways captures a frame on every wrapping, it is the default policy.
+func FramesAlways() FramePolicy {
+	return framesAlways{}
+}
+
+// FramesNever captures no frames, as if every wrapping was done with OmitFrame.
+func FramesNever() FramePolicy {
+	return framesNever{}
+}
+
+// FramesSampled captures a frame on one in every n wrappings, starting with the first.
+// Each policy returned keeps its own count, n of 1 or less is the same as FramesAlways.
+func FramesSampled(n uint64) FramePolicy {
+	if n <= 1 {
+		return framesAlways{}
+	}
+	return &framesSampled{n: n}
+}
+
+// FramesFirstWrap captures a frame only if the wrapped chain holds no FrameError yet.
+// The frame is then that of the innermost wrapping, where the failure originated.
+// Wrappings of this package record if their chain holds one, so only chains of other errors are walked for it.
+func FramesFirstWrap() FramePolicy {
+	return framesFirstWrap{}
+}
+
+// framePolicies is an immutable snapshot of the global and per package policies.
+type framePolicies struct {
+	global   FramePolicy
+	packages map[string]FramePolicy
+}
+
+var (
+	framePoliciesMux sync.Mutex
+	// currentFramePolicies is nil while the policies are the default, so the common case requires no lookups.
+	currentFramePolicies atomic.Pointer[framePolicies]
+)
+
+func updateFramePolicies(f func(*framePolicies)) {
+	framePoliciesMux.Lock()
+	defer framePoliciesMux.Unlock()
+
+	next := &framePolicies{global: framesAlways{}, packages: make(map[string]FramePolicy)}
+	if current := currentFramePolicies.Load(); current != nil {
+		next.global = current.global
+		for pkg, p := range current.packages {
+			next.packages[pkg] = p
+		}
+	}
+
+	f(next)
+
+	if _, ok := next.global.(framesAlways); ok && len(next.packages) == 0 {
+		next = nil
+	}
+	currentFramePolicies.Store(next)
+}
+
+// SetFramePolicy sets the policy of all wrappings, except those in packages with their own policy.
+// A nil policy resets it to FramesAlways. It is safe to be called concurrently with wrapping.
+func SetFramePolicy(p FramePolicy) {
+	if p == nil {
+		p = framesAlways{}
+	}
+
+	updateFramePolicies(func(policies *framePolicies) {
+		policies.global = p
+	})
+}
+
+// SetPackageFramePolicy sets the policy of the wrappings called from functions of the package, by import path.
+// It takes precedence over that of SetFramePolicy, a nil policy removes it.
+// Wrappings in packages with their own policy require looking up the caller, making them somewhat slower.
+func SetPackageFramePolicy(pkg string, p FramePolicy) {
+	updateFramePolicies(func(policies *framePolicies) {
+		if p == nil {
+			delete(policies.packages, pkg)
+		} else {
+			policies.packages[pkg] = p
+		}
+	})
+}
+
+// captureFrame reports if a frame is to be captured for a wrapping of err, as per the policies.
+// The skip argument is that of the caller whose package policy applies, as per caller.
+func captureFrame(skip uint8, err error) bool {
+	policies := currentFramePolicies.Load()
+	if policies == nil {
+		return true
+	}
+
+	policy := policies.global
+	if len(policies.packages) != 0 {
+		if p, ok := policies.packages[callerPackage(skip+1)]; ok {
+			policy = p
+		}
+	}
+
+	return policy.CaptureFrame(err)
+}
+
+// callerPackages caches the package of each caller seen by callerPackage.
+var callerPackages sync.Map
+
+func callerPackage(skip uint8) string {
+	frames := caller(skip + 1)
+	if pkg, ok := callerPackages.Load(frames); ok {
+		return pkg.(string)
+	}
+
+	function, _, _ := location(frames)
+	pkg := packageOf(function)
+	callerPackages.Store(frames, pkg)
+	return pkg
+}
+
+// packageOf returns the import path of the package of a function, as named by runtime.Frame.
+func packageOf(function string) string {
+	slash := strings.LastIndexByte(function, '/') + 1
+	if dot := strings.IndexByte(function[slash:], '.'); dot != -1 {
+		return function[:slash+dot]
+	}
+	return function
+}
@@ -0,0 +1,202 @@
+package xerrors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+const testPackage = "github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors_test"
+
+func countFrames(err error) int {
+	var count int
+	for layer := range xerrors.All(err) {
+		if xerrors.IsFrameError(layer) {
+			count++
+		}
+	}
+	return count
+}
+
+// causePolicy is a user defined FramePolicy, capturing frames only when wrapping errors wrapping no other.
+type causePolicy struct{}
+
+func (causePolicy) CaptureFrame(err error) bool {
+	return xerrors.Unwrap(err) == nil
+}
+
+func TestSetFramePolicy(t *testing.T) {
+	defer xerrors.SetFramePolicy(nil)
+
+	scenarios := []struct {
+		name           string
+		policy         xerrors.FramePolicy
+		expectedFrames int
+	}{
+		{
+			name:           "default",
+			policy:         nil,
+			expectedFrames: 4,
+		},
+		{
+			name:           "always",
+			policy:         xerrors.FramesAlways(),
+			expectedFrames: 4,
+		},
+		{
+			name:           "never",
+			policy:         xerrors.FramesNever(),
+			expectedFrames: 0,
+		},
+		{
+			name:           "sampled",
+			policy:         xerrors.FramesSampled(2),
+			expectedFrames: 2,
+		},
+		{
+			name:           "sampledOne",
+			policy:         xerrors.FramesSampled(1),
+			expectedFrames: 4,
+		},
+		{
+			name:           "firstWrap",
+			policy:         xerrors.FramesFirstWrap(),
+			expectedFrames: 1,
+		},
+		{
+			name:           "custom",
+			policy:         causePolicy{},
+			expectedFrames: 1,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			xerrors.SetFramePolicy(scenario.policy)
+
+			err := errors.New("cause")
+			for i := 0; i < 4; i++ {
+				err = xerrors.Wrap("wrap", err)
+			}
+
+			if frames := countFrames(err); frames != scenario.expectedFrames {
+				t.Fatalf("mismatched frames, expected %d got %d: %s", scenario.expectedFrames, frames, xerrors.DetailString(err))
+			}
+		})
+	}
+}
+
+func TestSetFramePolicy_firstWrap(t *testing.T) {
+	defer xerrors.SetFramePolicy(nil)
+	xerrors.SetFramePolicy(xerrors.FramesFirstWrap())
+
+	inner := xerrors.Wrap("inner", errors.New("cause"))
+	err := xerrors.Wrap("outer", inner)
+
+	if xerrors.LastFrameError(err) != xerrors.LastFrameError(inner) {
+		t.Fatalf("expected only the innermost frame, got %s", xerrors.DetailString(err))
+	}
+
+	// frames beneath wrappings not recording them, omitting them or rebuilt by Map, are still found
+	rebuilt := xerrors.Map(xerrors.Wrap("omitted", inner, xerrors.OmitFrame()), func(err error) error { return err })
+	for _, err := range []error{
+		xerrors.Wrap("outer", &notFoundError{Wrapping: xerrors.NewWrapping(inner, xerrors.OmitFrame())}),
+		xerrors.Wrap("outer", xerrors.Wrap("omitted", inner, xerrors.OmitFrame())),
+		xerrors.Wrap("outer", fmt.Errorf("foreign: %w", inner)),
+		xerrors.Wrap("outer", rebuilt),
+	} {
+		if frames := countFrames(err); frames != 1 {
+			t.Fatalf("expected only the innermost frame, got %s", xerrors.DetailString(err))
+		}
+	}
+}
+
+func TestSetFramePolicy_omitFrame(t *testing.T) {
+	defer xerrors.SetFramePolicy(nil)
+	xerrors.SetFramePolicy(xerrors.FramesAlways())
+
+	if err := xerrors.Wrap("wrap", errors.New("cause"), xerrors.OmitFrame()); countFrames(err) != 0 {
+		t.Fatalf("expected OmitFrame to take precedence, got %s", xerrors.DetailString(err))
+	}
+}
+
+func TestSetPackageFramePolicy(t *testing.T) {
+	defer xerrors.SetFramePolicy(nil)
+	defer xerrors.SetPackageFramePolicy(testPackage, nil)
+
+	xerrors.SetFramePolicy(xerrors.FramesAlways())
+	xerrors.SetPackageFramePolicy(testPackage, xerrors.FramesNever())
+
+	if err := xerrors.Wrap("wrap", errors.New("cause")); countFrames(err) != 0 {
+		t.Fatalf("expected the package policy to take precedence, got %s", xerrors.DetailString(err))
+	}
+
+	xerrors.SetFramePolicy(xerrors.FramesNever())
+	xerrors.SetPackageFramePolicy(testPackage, xerrors.FramesAlways())
+
+	if err := xerrors.Wrap("wrap", errors.New("cause")); countFrames(err) != 1 {
+		t.Fatalf("expected the package policy to take precedence, got %s", xerrors.DetailString(err))
+	}
+
+	xerrors.SetPackageFramePolicy(testPackage, nil)
+
+	if err := xerrors.Wrap("wrap", errors.New("cause")); countFrames(err) != 0 {
+		t.Fatalf("expected the global policy once the package one is removed, got %s", xerrors.DetailString(err))
+	}
+
+	// other packages are unaffected by the package policy
+	xerrors.SetFramePolicy(nil)
+	xerrors.SetPackageFramePolicy("example.com/other", xerrors.FramesNever())
+
+	if err := xerrors.Wrap("wrap", errors.New("cause")); countFrames(err) != 1 {
+		t.Fatalf("expected a frame, got %s", xerrors.DetailString(err))
+	}
+	xerrors.SetPackageFramePolicy("example.com/other", nil)
+}
+
+func TestSetFramePolicy_concurrent(t *testing.T) {
+	defer xerrors.SetFramePolicy(nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			xerrors.SetFramePolicy(xerrors.FramesSampled(3))
+			xerrors.SetPackageFramePolicy(testPackage, xerrors.FramesNever())
+			xerrors.SetPackageFramePolicy(testPackage, nil)
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		_ = xerrors.Wrap("wrap", errors.New("cause"))
+	}
+	<-done
+}
+
+func BenchmarkWrap_framePolicy(b *testing.B) {
+	defer xerrors.SetFramePolicy(nil)
+	defer xerrors.SetPackageFramePolicy(testPackage, nil)
+
+	cause := errors.New("cause")
+
+	for _, scenario := range []struct {
+		name  string
+		setup func()
+	}{
+		{name: "default", setup: func() {}},
+		{name: "never", setup: func() { xerrors.SetFramePolicy(xerrors.FramesNever()) }},
+		{name: "package", setup: func() { xerrors.SetPackageFramePolicy(testPackage, xerrors.FramesNever()) }},
+	} {
+		b.Run(scenario.name, func(b *testing.B) {
+			scenario.setup()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = xerrors.Wrap("wrap", cause)
+			}
+		})
+	}
+}
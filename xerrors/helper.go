@@ -0,0 +1,109 @@
+package xerrors
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// helpers is an immutable snapshot of the functions and packages marked as helpers.
+type helpers struct {
+	functions map[string]struct{}
+	packages  map[string]struct{}
+}
+
+func (h *helpers) isHelper(function string) bool {
+	if _, ok := h.functions[function]; ok {
+		return true
+	}
+	_, ok := h.packages[packageOf(function)]
+	return ok
+}
+
+var (
+	helpersMux sync.Mutex
+	// currentHelpers is nil while no helpers are marked, so the common case requires no lookups.
+	currentHelpers atomic.Pointer[helpers]
+)
+
+func updateHelpers(f func(*helpers)) {
+	helpersMux.Lock()
+	defer helpersMux.Unlock()
+
+	next := &helpers{functions: make(map[string]struct{}), packages: make(map[string]struct{})}
+	if current := currentHelpers.Load(); current != nil {
+		for function := range current.functions {
+			next.functions[function] = struct{}{}
+		}
+		for pkg := range current.packages {
+			next.packages[pkg] = struct{}{}
+		}
+	}
+
+	f(next)
+
+	if len(next.functions) == 0 && len(next.packages) == 0 {
+		next = nil
+	}
+	currentHelpers.Store(next)
+}
+
+// Helper marks the calling function as a helper, akin to testing.T.Helper.
+// The frames of NewWrapping, Wrap and the other wrapping constructors skip helpers, reporting their first non-helper caller.
+// It is intended for functions wrapping errors on behalf of their callers, and need only be called once per function.
+// Frames skipped with SkipNFrames are skipped first, and helpers then from the frame it lands on.
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+
+	if current := currentHelpers.Load(); current != nil {
+		if _, ok := current.functions[frame.Function]; ok {
+			return
+		}
+	}
+
+	updateHelpers(func(h *helpers) {
+		h.functions[frame.Function] = struct{}{}
+	})
+}
+
+// SetHelperPackage marks or unmarks all functions of the package, by import path, as helpers, as per Helper.
+// It is intended for packages of wrapping utilities or generated error constructors.
+func SetHelperPackage(pkg string, helper bool) {
+	updateHelpers(func(h *helpers) {
+		if helper {
+			h.packages[pkg] = struct{}{}
+		} else {
+			delete(h.packages, pkg)
+		}
+	})
+}
+
+// maxHelperFrames bounds the helpers skipped, beyond which the last of them is reported.
+const maxHelperFrames = 32
+
+// helperFrames returns how many consecutive helpers there are starting from the caller, as per caller.
+func helperFrames(skip uint8) uint8 {
+	h := currentHelpers.Load()
+	if h == nil {
+		return 0
+	}
+
+	var pcs [maxHelperFrames]uintptr
+	n := runtime.Callers(int(skip)+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var count uint8
+	for count < maxHelperFrames && int(skip)+int(count) < 0xff {
+		frame, more := frames.Next()
+		if !h.isHelper(frame.Function) || !more {
+			break
+		}
+		count++
+	}
+
+	return count
+}
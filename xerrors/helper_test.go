@@ -0,0 +1,122 @@
+package xerrors_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func wrapHelper(msg string, err error) error {
+	xerrors.Helper()
+	return xerrors.Wrap(msg, err)
+}
+
+func nestedWrapHelper(msg string, err error) error {
+	xerrors.Helper()
+	return wrapHelper(msg, err)
+}
+
+func notAHelper(msg string, err error) error {
+	return xerrors.Wrap(msg, err)
+}
+
+type helperConstructedError struct {
+	xerrors.Wrapping
+}
+
+func (*helperConstructedError) Error() string { return "constructed" }
+
+func newHelperConstructedError(err error) error {
+	xerrors.Helper()
+	return &helperConstructedError{Wrapping: xerrors.NewWrapping(err)}
+}
+
+func frameFunction(t *testing.T, err error) string {
+	t.Helper()
+
+	fErr := xerrors.LastFrameError(err)
+	if fErr == nil {
+		t.Fatalf("expected a frame, got %s", xerrors.DetailString(err))
+	}
+	function, _, _ := fErr.FrameLocation()
+	return function
+}
+
+func TestHelper(t *testing.T) {
+	const expectedFunction = testPackage + ".TestHelper"
+
+	scenarios := []struct {
+		name             string
+		err              error
+		expectedFunction string
+	}{
+		{
+			name:             "helper",
+			err:              wrapHelper("msg", errors.New("cause")),
+			expectedFunction: expectedFunction,
+		},
+		{
+			name:             "nestedHelper",
+			err:              nestedWrapHelper("msg", errors.New("cause")),
+			expectedFunction: expectedFunction,
+		},
+		{
+			name:             "newWrapping",
+			err:              newHelperConstructedError(errors.New("cause")),
+			expectedFunction: expectedFunction,
+		},
+		{
+			name:             "notAHelper",
+			err:              notAHelper("msg", errors.New("cause")),
+			expectedFunction: testPackage + ".notAHelper",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if function := frameFunction(t, scenario.err); function != scenario.expectedFunction {
+				t.Fatalf("mismatched function, expected %q got %q", scenario.expectedFunction, function)
+			}
+		})
+	}
+}
+
+func TestHelper_skipNFrames(t *testing.T) {
+	skipping := func() error {
+		return wrapHelper("msg", errors.New("cause"))
+	}
+
+	// SkipNFrames is applied first, skipping the helper itself, then helpers are skipped, of which there are none left
+	err := func() error {
+		xerrors.Helper()
+		return xerrors.Wrap("msg", errors.New("cause"), xerrors.SkipNFrames(1))
+	}()
+	if function, expected := frameFunction(t, err), testPackage+".TestHelper_skipNFrames"; function != expected {
+		t.Fatalf("mismatched function, expected %q got %q", expected, function)
+	}
+
+	if function, expected := frameFunction(t, skipping()), testPackage+".TestHelper_skipNFrames.func1"; function != expected {
+		t.Fatalf("mismatched function, expected %q got %q", expected, function)
+	}
+}
+
+func TestSetHelperPackage(t *testing.T) {
+	defer xerrors.SetHelperPackage(testPackage, false)
+
+	xerrors.SetHelperPackage(testPackage, true)
+	err := notAHelper("msg", errors.New("cause"))
+	xerrors.SetHelperPackage(testPackage, false)
+
+	// all functions of the package are skipped, the frame is that of the test runner
+	if function, expected := frameFunction(t, err), "testing.tRunner"; function != expected {
+		t.Fatalf("mismatched function, expected %q got %q", expected, function)
+	}
+
+	err = notAHelper("msg", errors.New("cause"))
+	if function, expected := frameFunction(t, err), testPackage+".notAHelper"; function != expected {
+		t.Fatalf("mismatched function, expected %q got %q", expected, function)
+	}
+}
@@ -0,0 +1,252 @@
+package xerrors
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+var logfmtSeparator = []byte(" ")
+
+const hexDigits = "0123456789abcdef"
+
+// MultiKeyValuer is an error holding key-value pairs.
+// Key-value serializers, such as that of NewLogfmtSerializer, serialise these pairs natively instead of Error.
+type MultiKeyValuer interface {
+	Wrapper
+	MultiKeyValue() [][2]string
+}
+
+type logfmtOptions struct {
+	prefix       string
+	keyFunc      func(index int, name string) string
+	taggedFields bool
+}
+
+// LogfmtOptionFunc represent optional arguments to NewLogfmtSerializer.
+type LogfmtOptionFunc = func(logfmtOptions) logfmtOptions
+
+// WithLogfmtPrefix replaces the default 'err' prefix of the keys.
+func WithLogfmtPrefix(prefix string) LogfmtOptionFunc {
+	return func(opts logfmtOptions) logfmtOptions {
+		opts.prefix = prefix
+		return opts
+	}
+}
+
+// WithLogfmtKeys replaces the default key scheme, '{prefix}.{index}.{name}'.
+// The index is that of the error in the chain, ignoring FrameErrors, the name either 'msg', 'type', 'frame' or a key of
+// a Fielder or MultiKeyValuer. The keys returned are sanitised as all others, with unsupported characters replaced by '_'.
+func WithLogfmtKeys(keyFunc func(index int, name string) string) LogfmtOptionFunc {
+	return func(opts logfmtOptions) logfmtOptions {
+		opts.keyFunc = keyFunc
+		return opts
+	}
+}
+
+// WithLogfmtTaggedFields additionally serialises the TaggedFields of errors that are neither Fielder nor MultiKeyValuer,
+// as pairs following their msg pair.
+func WithLogfmtTaggedFields() LogfmtOptionFunc {
+	return func(opts logfmtOptions) logfmtOptions {
+		opts.taggedFields = true
+		return opts
+	}
+}
+
+type logfmtSerializer struct {
+	opts       logfmtOptions
+	index      int
+	firstEntry bool
+
+	// frame is an auxiliary buffer, kept to minimise memory allocations
+	frame bytes.Buffer
+
+	// trimmer applies to frames if set, which are otherwise serialised in full
+	trimmer FrameTrimmer
+
+	// maxMessageLength and ellipsis are those of the Printer, if created WithMaxMessageLength, see message.
+	maxMessageLength int
+	ellipsis         string
+}
+
+func (s *logfmtSerializer) UseFrameTrimmer(t FrameTrimmer) {
+	s.trimmer = t
+}
+
+// truncateMessages has messages truncated before they are quoted, so the Printer does not cut the closing quote.
+func (s *logfmtSerializer) truncateMessages(length int, ellipsis string) bool {
+	s.maxMessageLength, s.ellipsis = length, ellipsis
+	return true
+}
+
+// message returns msg truncated as per the Printer's WithMaxMessageLength, if set.
+func (s *logfmtSerializer) message(msg string) string {
+	if s.maxMessageLength == 0 {
+		return msg
+	}
+	return truncatedMessage(msg, s.maxMessageLength, s.ellipsis)
+}
+
+func (s *logfmtSerializer) Keep(err error) bool {
+	return !IsFrameError(err)
+}
+
+func (s *logfmtSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	switch tErr := err.(type) {
+	case Fielder:
+		s.writeFields(buf, tErr.Fields())
+	case MultiKeyValuer:
+		for i, kv := range tErr.MultiKeyValue() {
+			if i != 0 {
+				buf.Write(logfmtSeparator)
+			}
+			s.writePair(buf, kv[0], s.message(kv[1]))
+		}
+	default:
+		s.writePair(buf, "msg", s.message(err.Error()))
+		if s.opts.taggedFields {
+			if fields := TaggedFields(err); len(fields) != 0 {
+				buf.Write(logfmtSeparator)
+				s.writeFields(buf, fields)
+			}
+		}
+	}
+
+	buf.Write(logfmtSeparator)
+	s.writePair(buf, "type", typeName(err))
+
+	if frameErr, ok := Reveal(Unwrap(err)).(FrameError); ok {
+		buf.Write(logfmtSeparator)
+		s.writeKey(buf, "frame")
+		buf.WriteByte('=')
+
+		function, file, line := frameErr.FrameLocation()
+		if s.trimmer != nil {
+			function, file = s.trimmer(function, file)
+		}
+		formatFrames(function, file, line, &s.frame)
+		writeLogfmtValue(buf, s.frame.String())
+		s.frame.Reset()
+	}
+
+	return true
+}
+
+func (s *logfmtSerializer) writeFields(buf *bytes.Buffer, fields []Field) {
+	for i, field := range fields {
+		if i != 0 {
+			buf.Write(logfmtSeparator)
+		}
+		s.writeKey(buf, field.Key)
+		buf.WriteByte('=')
+		writeLogfmtFieldValue(buf, field.Value)
+	}
+}
+
+func (s *logfmtSerializer) writePair(buf *bytes.Buffer, name, value string) {
+	s.writeKey(buf, name)
+	buf.WriteByte('=')
+	writeLogfmtValue(buf, value)
+}
+
+func (s *logfmtSerializer) writeKey(buf *bytes.Buffer, name string) {
+	if s.opts.keyFunc != nil {
+		writeLogfmtKey(buf, s.opts.keyFunc(s.index, name))
+		return
+	}
+
+	writeLogfmtKey(buf, s.opts.prefix)
+	buf.WriteByte('.')
+	buf.WriteString(strconv.Itoa(s.index))
+	buf.WriteByte('.')
+	writeLogfmtKey(buf, name)
+}
+
+// writeLogfmtKey writes the key, replacing characters not allowed in logfmt keys by '_'.
+func writeLogfmtKey(buf *bytes.Buffer, key string) {
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f {
+			r = '_'
+		}
+		buf.WriteRune(r)
+	}
+}
+
+func logfmtNeedsQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// writeLogfmtValue writes the value, quoting and escaping it if required.
+// Escaping is JSON-like, invalid UTF-8 is replaced by the unicode replacement character.
+func writeLogfmtValue(buf *bytes.Buffer, value string) {
+	if !logfmtNeedsQuoting(value) {
+		buf.WriteString(value)
+		return
+	}
+
+	buf.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < ' ' || r == 0x7f:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hexDigits[r>>4])
+			buf.WriteByte(hexDigits[r&0xf])
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+func (s *logfmtSerializer) Append(w io.Writer, b []byte) error {
+	if s.firstEntry {
+		s.firstEntry = false
+	} else if _, err := w.Write(logfmtSeparator); err != nil {
+		return err
+	}
+
+	s.index++
+
+	_, err := w.Write(b)
+	return err
+}
+
+func (s *logfmtSerializer) Reset() {
+	s.index = 0
+	s.firstEntry = true
+}
+
+// NewLogfmtSerializer provides a serializer producing logfmt key-value pairs, separated by a whitespace.
+// Each error produces '{prefix}.{index}.msg' and '{prefix}.{index}.type' pairs, plus '{prefix}.{index}.frame' if it
+// wraps a FrameError, which is not otherwise serialised. Fielder and MultiKeyValuer errors produce one pair per field or
+// key-value instead of the msg pair, fields in their native form. Values are quoted and escaped as required.
+// Printers created WithMaxMessageLength truncate the messages and key-value values before they are quoted.
+func NewLogfmtSerializer(opts ...LogfmtOptionFunc) Serializer {
+	logfmtOpts := logfmtOptions{prefix: "err"}
+	for _, opt := range opts {
+		logfmtOpts = opt(logfmtOpts)
+	}
+
+	return &logfmtSerializer{
+		opts:       logfmtOpts,
+		firstEntry: true,
+	}
+}
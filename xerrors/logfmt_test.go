@@ -0,0 +1,154 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+type kvError struct {
+	kvs [][2]string
+	xerrors.Wrapping
+}
+
+func (*kvError) Error() string { return "kv" }
+
+func (err *kvError) MultiKeyValue() [][2]string { return err.kvs }
+
+var _ xerrors.MultiKeyValuer = (*kvError)(nil)
+
+// beware, the line of the Wrap call in this method is required to test frame logic
+func logfmtFramedError() error {
+	return xerrors.Wrap("outer", xerrors.New("cause"))
+}
+
+func TestLogfmtSerializer(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		opts        []xerrors.LogfmtOptionFunc
+		expectedOut string
+	}{
+		{
+			name:        "nonWrapped",
+			err:         xerrors.New("msg"),
+			expectedOut: "err.0.msg=msg err.0.type=xerrors.baseError",
+		},
+		{
+			name: "quoting",
+			err: xerrors.Wrap(
+				`a "quoted" a=b path\to`,
+				xerrors.Wrap("line\nbreak\x01", xerrors.New(""), xerrors.OmitFrame()),
+				xerrors.OmitFrame(),
+			),
+			expectedOut: `err.0.msg="a \"quoted\" a=b path\\to" err.0.type=*xerrors.wrappingError ` +
+				`err.1.msg="line\nbreak\u0001" err.1.type=*xerrors.wrappingError ` +
+				`err.2.msg="" err.2.type=xerrors.baseError`,
+		},
+		{
+			name: "keyValues",
+			err: &kvError{
+				kvs:      [][2]string{{"user id", "42"}, {"email", "foo@bar.com"}},
+				Wrapping: xerrors.NewWrapping(xerrors.New("msg"), xerrors.OmitFrame()),
+			},
+			expectedOut: "err.0.user_id=42 err.0.email=foo@bar.com err.0.type=*xerrors_test.kvError " +
+				"err.1.msg=msg err.1.type=xerrors.baseError",
+		},
+		{
+			name:        "prefix",
+			err:         xerrors.New("msg"),
+			opts:        []xerrors.LogfmtOptionFunc{xerrors.WithLogfmtPrefix("error")},
+			expectedOut: "error.0.msg=msg error.0.type=xerrors.baseError",
+		},
+		{
+			name: "keys",
+			err:  xerrors.Wrap("outer", xerrors.New("msg"), xerrors.OmitFrame()),
+			opts: []xerrors.LogfmtOptionFunc{xerrors.WithLogfmtKeys(func(index int, name string) string {
+				return name + strconv.Itoa(index)
+			})},
+			expectedOut: "msg0=outer type0=*xerrors.wrappingError msg1=msg type1=xerrors.baseError",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(func() xerrors.Serializer { return xerrors.NewLogfmtSerializer(scenario.opts...) })
+
+			// twice, to validate Reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if err := printer.Write(&buf, scenario.err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLogfmtSerializer_frame(t *testing.T) {
+	printer := xerrors.NewPrinter(func() xerrors.Serializer { return xerrors.NewLogfmtSerializer() })
+
+	buf := bytes.Buffer{}
+	if err := printer.Write(&buf, logfmtFramedError()); err != nil {
+		t.Fatalf("error serialising error: %s", err)
+	}
+
+	expectedPrefix := "err.0.msg=outer err.0.type=*xerrors.wrappingError " +
+		"err.0.frame=github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors_test.logfmtFramedError:"
+	expectedSuffix := "/xerrors/logfmt_test.go:25 err.1.msg=cause err.1.type=xerrors.baseError"
+
+	if out := buf.String(); !strings.HasPrefix(out, expectedPrefix) || !strings.HasSuffix(out, expectedSuffix) {
+		t.Fatalf("mismatched output, expected %q...%q got %q", expectedPrefix, expectedSuffix, out)
+	}
+}
+
+func TestLogfmtSerializer_frameTrimmer(t *testing.T) {
+	printer := xerrors.NewPrinter(
+		func() xerrors.Serializer { return xerrors.NewLogfmtSerializer() },
+		xerrors.WithFrameTrimmer(xerrors.TrimBase()),
+	)
+
+	buf := bytes.Buffer{}
+	if err := printer.Write(&buf, logfmtFramedError()); err != nil {
+		t.Fatalf("error serialising error: %s", err)
+	}
+
+	expected := "err.0.msg=outer err.0.type=*xerrors.wrappingError " +
+		"err.0.frame=xerrors_test.logfmtFramedError:logfmt_test.go:25 err.1.msg=cause err.1.type=xerrors.baseError"
+	if out := buf.String(); out != expected {
+		t.Fatalf("mismatched output, expected %q got %q", expected, out)
+	}
+}
+
+func TestLogfmtSerializer_maxMessageLength(t *testing.T) {
+	printer := xerrors.NewPrinter(
+		func() xerrors.Serializer { return xerrors.NewLogfmtSerializer() },
+		xerrors.WithMaxMessageLength(9),
+	)
+
+	err := xerrors.Wrap(
+		"hello world",
+		&kvError{kvs: [][2]string{{"key", "a quoted value"}}},
+		xerrors.OmitFrame(),
+	)
+
+	buf := bytes.Buffer{}
+	if wErr := printer.Write(&buf, err); wErr != nil {
+		t.Fatalf("error serialising error: %s", wErr)
+	}
+
+	expectedOut := `err.0.msg="hello wor..." err.0.type=*xerrors.wrappingError ` +
+		`err.1.key="a quoted ..." err.1.type=*xerrors_test.kvError`
+	if buf.String() != expectedOut {
+		t.Fatalf("mismatched output, expected %q got %q", expectedOut, buf.String())
+	}
+}
@@ -0,0 +1,41 @@
+package xerrors
+
+// Opaque hides the error chain of err from inspection, while preserving its serialised form.
+// The returned error wraps nothing, so Unwrap, Last, Cause, Similar and Contains do not see past it.
+// Its Error method returns String(err), so it behaves like a plain string error to code unaware of it.
+//
+// Printer sees through opaque errors, so serialising one produces the same output as serialising err.
+// Serializers that walk the chain themselves should use Reveal to do the same.
+func Opaque(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &opaqueError{err: err}
+}
+
+type opaqueError struct {
+	err error
+}
+
+func (err *opaqueError) Error() string {
+	return String(err.err)
+}
+
+func (*opaqueError) Unwrap() error {
+	return nil
+}
+
+var _ Wrapper = (*opaqueError)(nil)
+
+// Reveal returns the error hidden by Opaque, or err itself if it is not opaque.
+// It is an escape hatch meant for debug serializers only, regular code must not rely on what an opaque error hides.
+func Reveal(err error) error {
+	for {
+		oErr, ok := err.(*opaqueError)
+		if !ok {
+			return err
+		}
+		err = oErr.err
+	}
+}
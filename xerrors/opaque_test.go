@@ -0,0 +1,53 @@
+package xerrors_test
+
+import (
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func TestOpaque(t *testing.T) {
+	if xerrors.Opaque(nil) != nil {
+		t.Fatal("expected Opaque of nil to be nil")
+	}
+
+	inner := xerrors.Wrap("wrapper", fooError{xerrors.NewWrapping(xerrors.New("msg"), xerrors.OmitFrame())})
+	err := xerrors.Wrap("outer", xerrors.Opaque(inner), xerrors.OmitFrame())
+
+	if out, expectedOut := xerrors.String(err), "outer: wrapper: foo: msg"; out != expectedOut {
+		t.Fatalf("mismatched String output, expected %q got %q", expectedOut, out)
+	}
+
+	if out, expectedOut := xerrors.DetailString(err), "outer: "+xerrors.DetailString(inner); out != expectedOut {
+		t.Fatalf("mismatched DetailString output, expected %q got %q", expectedOut, out)
+	}
+
+	opaque := xerrors.Unwrap(err)
+	if xerrors.Unwrap(opaque) != nil {
+		t.Fatal("opaque errors must not expose their chain through Unwrap")
+	}
+
+	if opaque.Error() != "wrapper: foo: msg" {
+		t.Fatalf("expected opaque error message to be the full inner message, got %q", opaque.Error())
+	}
+
+	if xerrors.Last(err, func(err error) bool { _, ok := err.(Fooer); return ok }) != nil {
+		t.Fatal("Last must not find errors hidden by Opaque")
+	}
+
+	if xerrors.Cause(err) != opaque {
+		t.Fatal("the cause of an opaque error must be itself")
+	}
+
+	if xerrors.Contains(err, xerrors.New("msg")) {
+		t.Fatal("Contains must not find errors hidden by Opaque")
+	}
+
+	if xerrors.Reveal(opaque) != inner {
+		t.Fatal("Reveal must return the error hidden by Opaque")
+	}
+
+	if xerrors.Reveal(inner) != inner {
+		t.Fatal("Reveal must return non-opaque errors unmodified")
+	}
+}
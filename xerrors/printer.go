@@ -4,22 +4,254 @@ import (
 	"bytes"
 	"io"
 	"sync"
+	"unicode/utf8"
 )
 
+// DefaultEllipsis is the marker written after truncated messages, unless overridden with WithEllipsis.
+const DefaultEllipsis = "..."
+
 type printerAlloc struct {
-	buf bytes.Buffer
-	s   Serializer
+	buf     bytes.Buffer
+	counter countingWriter
+	s       Serializer
+	// reverse is set if the errors are presented to s innermost first, see serializerOrder.
+	reverse bool
+	// layout is set if s is a layoutUser, see usesLayout.
+	layout bool
+	// truncatesMessages is set if s truncates messages itself, see messageTruncator.
+	truncatesMessages bool
+	// errs holds the walked errors of reverse Printers, and the kept errors of those of layoutUser Serializers.
+	errs []error
+	// depths holds the depths of the errors in errs, for layoutUser Serializers.
+	depths []int
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += n
+	return n, err
+}
+
+type printerOptions struct {
+	maxDepth         int
+	maxMessageLength int
+	maxLength        int
+	ellipsis         string
+	formatters       *FormatterRegistry
+	trimmer          FrameTrimmer
+	reverse          bool
+}
+
+// PrinterOptionFunc represent optional arguments to NewPrinter.
+type PrinterOptionFunc = func(printerOptions) printerOptions
+
+// WithMaxDepth overrides MaxDepth for the printer.
+// Errors beyond it are not serialised, a '...(N more)' marker is serialised in their place.
+// Serializers laying out the whole chain, such as that of NewTreeSerializer, do so within the same depth.
+func WithMaxDepth(depth int) PrinterOptionFunc {
+	return func(opts printerOptions) printerOptions {
+		opts.maxDepth = depth
+		return opts
+	}
+}
+
+// WithMaxMessageLength truncates the message of each error to at most length bytes, followed by the ellipsis.
+// The message is that produced by the Serializer's CustomFormat or the error's Error, before Append.
+// Serializers whose output would be invalid if truncated, such as that of NewLogfmtSerializer, instead truncate the
+// message of the error before formatting it.
+func WithMaxMessageLength(length int) PrinterOptionFunc {
+	return func(opts printerOptions) printerOptions {
+		opts.maxMessageLength = length
+		return opts
+	}
+}
+
+// WithMaxLength limits the serialised output to approximately length bytes.
+// The message exceeding it is truncated and followed by the ellipsis, no further errors are serialised.
+// The output may exceed length by the ellipsis and whatever the Serializer appends to that last message.
+func WithMaxLength(length int) PrinterOptionFunc {
+	return func(opts printerOptions) printerOptions {
+		opts.maxLength = length
+		return opts
+	}
+}
+
+// WithEllipsis overrides DefaultEllipsis as the marker written after truncated messages.
+func WithEllipsis(ellipsis string) PrinterOptionFunc {
+	return func(opts printerOptions) printerOptions {
+		opts.ellipsis = ellipsis
+		return opts
+	}
+}
+
+// WithFormatters provides the registry to the printer's Serializer, if it is a FormatterUser.
+// Use it to scope formatters to a Printer, instead of registering them globally.
+func WithFormatters(r *FormatterRegistry) PrinterOptionFunc {
+	return func(opts printerOptions) printerOptions {
+		opts.formatters = r
+		return opts
+	}
+}
+
+// WithFrameTrimmer provides the trimmer to the printer's Serializer, if it is a FrameTrimmerUser.
+// It configures how frame locations are presented, for instance relative to the module with TrimModule.
+// Without it each Serializer applies its own default: TrimBase for the colon and tree serializers,
+// while that of NewLogfmtSerializer presents frames untrimmed, as they are meant for machines rather than people.
+func WithFrameTrimmer(t FrameTrimmer) PrinterOptionFunc {
+	return func(opts printerOptions) printerOptions {
+		opts.trimmer = t
+		return opts
+	}
+}
+
+// WithReverseOrder serialises errors innermost first, the root cause leading and the outermost error last.
+// Kept errors are presented to the Serializer in that order, and a truncation marker is serialised first,
+// in place of the innermost errors it stands for.
+// Serializers whose output depends on the order, such as that of NewColonDetailedSerializer, have reverse variants.
+// Those laying out the whole chain, such as that of NewTreeSerializer, are presented errors outermost first regardless.
+// Reverse is the equivalent for a single Serializer.
+func WithReverseOrder() PrinterOptionFunc {
+	return func(opts printerOptions) printerOptions {
+		opts.reverse = true
+		return opts
+	}
+}
+
+// orderer is implemented by Serializers with a say on the order Printers present errors to them in.
+// order is given if the Printer is to present them innermost first, and returns if it is to do so.
+type orderer interface {
+	order(reverse bool) bool
+}
+
+// serializerOrder returns if s is to be presented errors innermost first, reverse unless s is an orderer.
+func serializerOrder(s Serializer, reverse bool) bool {
+	if o, ok := s.(orderer); ok {
+		return o.order(reverse)
+	}
+	return reverse
+}
+
+// depthUser is implemented by Serializers walking errors themselves, so they do so within the Printer's WithMaxDepth.
+type depthUser interface {
+	useMaxDepth(depth int)
+}
+
+// useMaxDepth forwards the depth to the Serializer, if it is a depthUser.
+func useMaxDepth(s Serializer, depth int) {
+	if du, ok := s.(depthUser); ok {
+		du.useMaxDepth(depth)
+	}
+}
+
+// rootUser is implemented by Serializers whose handling of each error depends on the whole of the chain.
+// Printers pass them the error being serialised before presenting any of its errors, in whichever order.
+type rootUser interface {
+	useRoot(err error)
+}
+
+// useRoot forwards the error being serialised to the Serializer, if it is a rootUser.
+func useRoot(s Serializer, err error) {
+	if ru, ok := s.(rootUser); ok {
+		ru.useRoot(err)
+	}
+}
+
+// messageTruncator is implemented by Serializers whose output would be invalid if truncated, such as quoted values.
+// Printers created WithMaxMessageLength pass them the length and ellipsis, to truncate messages before formatting them.
+// If truncateMessages returns true Printers do not otherwise truncate the messages formatted by CustomFormat.
+type messageTruncator interface {
+	truncateMessages(length int, ellipsis string) bool
+}
+
+// truncateMessages forwards the length and ellipsis to the Serializer, if it is a messageTruncator.
+func truncateMessages(s Serializer, length int, ellipsis string) bool {
+	mt, ok := s.(messageTruncator)
+	return ok && mt.truncateMessages(length, ellipsis)
+}
+
+// truncatedMessage returns msg shortened to at most length bytes followed by the ellipsis, if longer than length.
+// It never splits UTF-8 characters.
+func truncatedMessage(msg string, length int, ellipsis string) string {
+	if length < 0 {
+		length = 0
+	}
+	if len(msg) <= length {
+		return msg
+	}
+	return msg[:runeStart(msg, length)] + ellipsis
+}
+
+// runeStart returns the highest index not greater than i at which no UTF-8 character is split, i being within b.
+func runeStart[T string | []byte](b T, i int) int {
+	for i > 0 && !utf8.RuneStart(b[i]) {
+		i--
+	}
+	return i
+}
+
+// layoutUser is implemented by Serializers laying out all errors before serialising any, such as that of NewTreeSerializer.
+// Printers walk all errors first, calling Keep once for each, and pass the depth of those kept to useLayout.
+// The errors are then presented outermost first, with truncation markers in place of the errors they stand for.
+// Laying out through the Printer, rather than walking the errors itself, the Serializer sees the errors the
+// Serializers decorating it keep, not just those it would.
+type layoutUser interface {
+	// usesLayout reports if the Serializer is to be given the layout, decorators forward it.
+	usesLayout() bool
+	useLayout(depths []int)
+}
+
+// usesLayout reports if s is a layoutUser that is to be given the layout.
+func usesLayout(s Serializer) bool {
+	lu, ok := s.(layoutUser)
+	return ok && lu.usesLayout()
+}
+
+// useLayout forwards the layout to the Serializer, if it is a layoutUser.
+func useLayout(s Serializer, depths []int) {
+	if lu, ok := s.(layoutUser); ok {
+		lu.useLayout(depths)
+	}
 }
 
 // NewPrinter initialises an error printer.
-func NewPrinter(fFactory func() Serializer) *Printer {
+// Truncation options are applied uniformly, whatever the Serializer, and never split UTF-8 characters.
+func NewPrinter(fFactory func() Serializer, opts ...PrinterOptionFunc) *Printer {
+	printerOpts := printerOptions{ellipsis: DefaultEllipsis}
+	for _, opt := range opts {
+		printerOpts = opt(printerOpts)
+	}
+
 	return &Printer{
+		opts: printerOpts,
 		pool: sync.Pool{
 			New: func() interface{} {
-				return &printerAlloc{
-					buf: bytes.Buffer{},
-					s:   fFactory(),
+				s := fFactory()
+				if printerOpts.formatters != nil {
+					useFormatters(s, printerOpts.formatters)
+				}
+				if printerOpts.trimmer != nil {
+					useFrameTrimmer(s, printerOpts.trimmer)
+				}
+				if printerOpts.maxDepth > 0 {
+					useMaxDepth(s, printerOpts.maxDepth)
 				}
+
+				alloc := &printerAlloc{
+					buf:     bytes.Buffer{},
+					s:       s,
+					reverse: serializerOrder(s, printerOpts.reverse),
+					layout:  usesLayout(s),
+				}
+				if printerOpts.maxMessageLength > 0 {
+					alloc.truncatesMessages = truncateMessages(s, printerOpts.maxMessageLength, printerOpts.ellipsis)
+				}
+				return alloc
 			},
 		},
 	}
@@ -28,6 +260,7 @@ func NewPrinter(fFactory func() Serializer) *Printer {
 // Printer is an error printer.
 // It is thin wrapper around a Serializer factory, this fully defines the output of the printer.
 type Printer struct {
+	opts printerOptions
 	pool sync.Pool
 }
 
@@ -37,11 +270,29 @@ type Printer struct {
 func (p *Printer) Write(w io.Writer, err error) error {
 	alloc := p.pool.Get().(*printerAlloc)
 
-	if err := p.write(w, alloc.s, err, &alloc.buf); err != nil {
+	if p.opts.maxLength > 0 {
+		alloc.counter = countingWriter{w: w}
+		w = &alloc.counter
+	}
+
+	useRoot(alloc.s, err)
+
+	var writeErr error
+	switch {
+	case alloc.layout:
+		writeErr = p.writeLaidOut(w, alloc, err)
+	case alloc.reverse:
+		writeErr = p.writeReverse(w, alloc, err)
+	default:
+		writeErr = p.write(w, alloc, err)
+	}
+	if writeErr != nil {
 		// do not return to the pool
-		return err
+		return writeErr
 	}
 
+	alloc.counter = countingWriter{}
+
 	alloc.s.Reset()
 
 	p.pool.Put(alloc)
@@ -49,19 +300,168 @@ func (p *Printer) Write(w io.Writer, err error) error {
 	return nil
 }
 
-func (*Printer) write(w io.Writer, s Serializer, err error, auxiliary *bytes.Buffer) error {
-	var ok bool
+// newWalker returns a walker revealing opaque errors, limited to the Printer's maximum depth.
+func (p *Printer) newWalker() walker {
+	if p.opts.maxDepth > 0 {
+		return newWalkerDepth(true, p.opts.maxDepth)
+	}
+	return newWalker(true)
+}
+
+// write serialises all errors kept by s, seeing through opaque errors.
+// If the walk is truncated, due to depth or cycles, a marker error is serialised last.
+// The Serializer is flushed at the end, if it is a Flusher.
+// If WithMaxLength is set, w is a *countingWriter.
+func (p *Printer) write(w io.Writer, alloc *printerAlloc, err error) error {
 	var writerErr error
+	var full bool
+
+	walk := p.newWalker()
 
-	for err = Last(err, s.Keep); err != nil; err = Last(Unwrap(err), s.Keep) {
-		ok = s.CustomFormat(err, auxiliary)
-		if !ok {
-			auxiliary.WriteString(err.Error())
+	walk.walk(err, 0, func(_ int, err error) bool {
+		if !alloc.s.Keep(err) {
+			return true
 		}
-		if writerErr = s.Append(w, auxiliary.Bytes()); writerErr != nil {
+		full, writerErr = p.append(w, alloc, err)
+		return writerErr == nil && !full
+	})
+	if writerErr != nil {
+		return writerErr
+	}
+
+	if walk.truncated() && !full {
+		_, writerErr = p.append(w, alloc, &truncatedError{
+			omitted:     walk.omitted,
+			omittedMore: walk.omittedMore,
+			cycle:       walk.cycle,
+		})
+		if writerErr != nil {
 			return writerErr
 		}
-		auxiliary.Reset()
 	}
-	return nil
+
+	return flush(alloc.s, w)
+}
+
+// writeReverse is the innermost first form of write, for Printers created WithReverseOrder or of Reverse Serializers.
+// The walked errors are held in the pooled alloc.errs, so no allocations are required once the pool is warm.
+func (p *Printer) writeReverse(w io.Writer, alloc *printerAlloc, err error) error {
+	defer func() {
+		clear(alloc.errs)
+		alloc.errs = alloc.errs[:0]
+	}()
+
+	walk := p.newWalker()
+
+	walk.walk(err, 0, func(_ int, err error) bool {
+		alloc.errs = append(alloc.errs, err)
+		return true
+	})
+
+	var writerErr error
+	var full bool
+
+	if walk.truncated() {
+		full, writerErr = p.append(w, alloc, &truncatedError{
+			omitted:     walk.omitted,
+			omittedMore: walk.omittedMore,
+			cycle:       walk.cycle,
+		})
+	}
+
+	for i := len(alloc.errs) - 1; i >= 0 && writerErr == nil && !full; i-- {
+		if !alloc.s.Keep(alloc.errs[i]) {
+			continue
+		}
+		full, writerErr = p.append(w, alloc, alloc.errs[i])
+	}
+	if writerErr != nil {
+		return writerErr
+	}
+
+	return flush(alloc.s, w)
+}
+
+// writeLaidOut is the form of write for layoutUser Serializers, walking all errors before serialising any.
+// The kept errors and their depths are held in the pooled alloc.errs and alloc.depths, with the truncation markers
+// in place, so no allocations are required for them once the pool is warm.
+func (p *Printer) writeLaidOut(w io.Writer, alloc *printerAlloc, err error) error {
+	defer func() {
+		clear(alloc.errs)
+		alloc.errs = alloc.errs[:0]
+		alloc.depths = alloc.depths[:0]
+	}()
+
+	walk := p.newWalker()
+	walk.mark = func(depth int, marker error) bool {
+		alloc.errs = append(alloc.errs, marker)
+		alloc.depths = append(alloc.depths, depth)
+		return true
+	}
+
+	walk.walk(err, 0, func(depth int, err error) bool {
+		if alloc.s.Keep(err) {
+			alloc.errs = append(alloc.errs, err)
+			alloc.depths = append(alloc.depths, depth)
+		}
+		return true
+	})
+
+	useLayout(alloc.s, alloc.depths)
+
+	for _, err := range alloc.errs {
+		full, writerErr := p.append(w, alloc, err)
+		if writerErr != nil {
+			return writerErr
+		}
+		if full {
+			break
+		}
+	}
+
+	return flush(alloc.s, w)
+}
+
+// append serialises a single error, applying the truncation options.
+// It returns true if the output has reached WithMaxLength, and no more errors are to be serialised.
+// The pooled alloc.buf holds the message in the meantime.
+func (p *Printer) append(w io.Writer, alloc *printerAlloc, err error) (bool, error) {
+	s, auxiliary := alloc.s, &alloc.buf
+
+	formatted := s.CustomFormat(err, auxiliary)
+	if !formatted {
+		auxiliary.WriteString(err.Error())
+	}
+
+	// messageTruncators have already truncated the messages they formatted
+	if p.opts.maxMessageLength > 0 && !(formatted && alloc.truncatesMessages) {
+		p.truncate(auxiliary, p.opts.maxMessageLength)
+	}
+
+	var full bool
+	if p.opts.maxLength > 0 {
+		remaining := p.opts.maxLength - w.(*countingWriter).n
+		full = p.truncate(auxiliary, remaining)
+	}
+
+	writerErr := s.Append(w, auxiliary.Bytes())
+	auxiliary.Reset()
+	return full, writerErr
+}
+
+// truncate shortens buf to at most length bytes followed by the ellipsis, without splitting UTF-8 characters.
+// It returns true if buf was truncated.
+func (p *Printer) truncate(buf *bytes.Buffer, length int) bool {
+	if length < 0 {
+		length = 0
+	}
+
+	b := buf.Bytes()
+	if len(b) <= length {
+		return false
+	}
+
+	buf.Truncate(runeStart(b, length))
+	buf.WriteString(p.opts.ellipsis)
+	return true
 }
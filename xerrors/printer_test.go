@@ -0,0 +1,159 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func TestPrinter_truncation(t *testing.T) {
+	err := xerrors.Wrap("outer", xerrors.Wrap("añadido", xerrors.New("a very long message"), xerrors.OmitFrame()), xerrors.OmitFrame())
+
+	scenarios := []struct {
+		name        string
+		opts        []xerrors.PrinterOptionFunc
+		expectedOut string
+	}{
+		{
+			name:        "noLimits",
+			opts:        nil,
+			expectedOut: "outer: añadido: a very long message",
+		},
+		{
+			name:        "maxMessageLength",
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxMessageLength(6)},
+			expectedOut: "outer: añadi...: a very...",
+		},
+		{
+			name:        "maxMessageLengthUTF8",
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxMessageLength(2)},
+			expectedOut: "ou...: a...: a ...",
+		},
+		{
+			name:        "maxMessageLengthEllipsis",
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxMessageLength(6), xerrors.WithEllipsis("…")},
+			expectedOut: "outer: añadi…: a very…",
+		},
+		{
+			name:        "maxLength",
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxLength(20)},
+			expectedOut: "outer: añadido: a ver...",
+		},
+		{
+			name:        "maxLengthUTF8",
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxLength(7)},
+			expectedOut: "outer: a...",
+		},
+		{
+			name:        "maxLengthExact",
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxLength(5)},
+			expectedOut: "outer: ...",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(xerrors.NewColonBasicSerializer, scenario.opts...)
+
+			// twice, to validate the pooled state is reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if err := printer.Write(&buf, err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPrinter_maxDepthTree(t *testing.T) {
+	err := multiError{[]error{
+		xerrors.Wrap("left", xerrors.New("left_cause"), xerrors.OmitFrame()),
+		xerrors.New("right"),
+	}}
+
+	printer := xerrors.NewPrinter(
+		func() xerrors.Serializer { return xerrors.WithPrefix(xerrors.NewTreeSerializer(), "") },
+		xerrors.WithMaxDepth(2),
+	)
+
+	buf := bytes.Buffer{}
+	if wErr := printer.Write(&buf, err); wErr != nil {
+		t.Fatalf("error serialising error: %s", wErr)
+	}
+
+	expected := "xerrors_test.multiError: multi\n" +
+		"├─ *xerrors.wrappingError: left\n" +
+		"│  └─ ...(1 more)\n" +
+		"└─ xerrors.baseError: right"
+	if buf.String() != expected {
+		t.Fatalf("mismatched output, expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestPrinter_decoratedTree(t *testing.T) {
+	err := multiError{[]error{
+		xerrors.Wrap("left", xerrors.Wrap("timeout", xerrors.Wrap("timeout", xerrors.New("dial"), xerrors.OmitFrame()), xerrors.OmitFrame()), xerrors.OmitFrame()),
+		xerrors.Wrap("right", xerrors.New("right_cause"), xerrors.OmitFrame()),
+	}}
+
+	scenarios := []struct {
+		name        string
+		factory     func() xerrors.Serializer
+		expectedOut string
+	}{
+		{
+			name: "filterKeep",
+			factory: func() xerrors.Serializer {
+				return xerrors.FilterKeep(xerrors.WithPrefix(xerrors.NewTreeSerializer(), ""), func(err error) bool {
+					return err.Error() != "right_cause"
+				})
+			},
+			expectedOut: "xerrors_test.multiError: multi\n" +
+				"├─ *xerrors.wrappingError: left\n" +
+				"│  └─ *xerrors.wrappingError: timeout\n" +
+				"│     └─ *xerrors.wrappingError: timeout\n" +
+				"│        └─ xerrors.baseError: dial\n" +
+				"└─ *xerrors.wrappingError: right",
+		},
+		{
+			name: "collapsing",
+			factory: func() xerrors.Serializer {
+				return xerrors.NewCollapsingSerializer(xerrors.WithPrefix(xerrors.NewTreeSerializer(), ""))
+			},
+			expectedOut: "xerrors_test.multiError: multi\n" +
+				"├─ *xerrors.wrappingError: left\n" +
+				"│  └─ *xerrors.wrappingError: timeout (x2)\n" +
+				"│     └─ xerrors.baseError: dial\n" +
+				"└─ *xerrors.wrappingError: right\n" +
+				"   └─ xerrors.baseError: right_cause",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(scenario.factory)
+
+			// twice, to validate the pooled state is reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if wErr := printer.Write(&buf, err); wErr != nil {
+					t.Fatalf("error serialising error: %s", wErr)
+				}
+
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected:\n%s\ngot:\n%s", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package xerrors
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+)
+
+// RedactedPlaceholder is written by redacting serializers in place of sensitive content.
+const RedactedPlaceholder = "[REDACTED]"
+
+// Sensitive is an error whose message must not be exposed by redacting serializers.
+// Its whole message is replaced by RedactedPlaceholder.
+type Sensitive interface {
+	Wrapper
+	Sensitive()
+}
+
+// IsSensitive is a helper for type casting to Sensitive
+func IsSensitive(err error) bool {
+	_, ok := err.(Sensitive)
+	return ok
+}
+
+// Redactable is an error whose message contains some sensitive values.
+// Redacting serializers use RedactedError instead of Error, leaving the non-sensitive parts of the message visible.
+type Redactable interface {
+	Wrapper
+	RedactedError() string
+}
+
+// WrapSensitive produces a wrapped string error whose message is sensitive in its entirety.
+// Wrapping and frame behaviour is identical to that of Wrap.
+func WrapSensitive(msg string, err error, opts ...WrapOptionFunc) error {
+	return &sensitiveError{
+		msg:      msg,
+		Wrapping: newWrapping(err, wrapOptions{skip: 1}, opts...),
+	}
+}
+
+type sensitiveError struct {
+	msg string
+	Wrapping
+}
+
+func (err *sensitiveError) Error() string {
+	return err.msg
+}
+
+func (*sensitiveError) Sensitive() {}
+
+var _ Sensitive = (*sensitiveError)(nil)
+
+// WrapRedactable produces a wrapped string error where every occurrence of the secrets within msg is sensitive.
+// Error returns msg unmodified, RedactedError replaces each secret with RedactedPlaceholder.
+// Wrapping and frame behaviour is identical to that of Wrap.
+func WrapRedactable(msg string, secrets []string, err error, opts ...WrapOptionFunc) error {
+	return &redactableError{
+		msg:      msg,
+		secrets:  secrets,
+		Wrapping: newWrapping(err, wrapOptions{skip: 1}, opts...),
+	}
+}
+
+type redactableError struct {
+	msg     string
+	secrets []string
+	Wrapping
+}
+
+func (err *redactableError) Error() string {
+	return err.msg
+}
+
+func (err *redactableError) RedactedError() string {
+	msg := err.msg
+	for _, secret := range err.secrets {
+		if secret == "" {
+			continue
+		}
+		msg = strings.Replace(msg, secret, RedactedPlaceholder, -1)
+	}
+	return msg
+}
+
+var _ Redactable = (*redactableError)(nil)
+
+// redactedError stands in for errors with redacted content when handed to the decorated Serializer.
+// It wraps the same error as the original so the decorated Serializer can still walk the chain.
+// Its fields are the original's TaggedFields, redacted, and are reported as such by TaggedFields.
+type redactedError struct {
+	original error
+	msg      string
+	fields   []Field
+	Wrapping
+}
+
+func (err *redactedError) Error() string {
+	return err.msg
+}
+
+// redactedFielderError is the redactedError of a Fielder, its fields are the original's Fields, redacted.
+type redactedFielderError struct {
+	redactedError
+}
+
+func (err *redactedFielderError) Fields() []Field {
+	return err.fields
+}
+
+var _ Fielder = (*redactedFielderError)(nil)
+
+// redact returns the stand-in of err with its redacted content, or err itself if it has none.
+func redact(err error) error {
+	if sErr, ok := err.(Sensitive); ok {
+		return &redactedError{original: err, msg: RedactedPlaceholder, Wrapping: Wrapping{err: sErr.Unwrap()}}
+	}
+
+	fErr, isFielder := err.(Fielder)
+	var fields []Field
+	if isFielder {
+		fields = fErr.Fields()
+	} else {
+		fields = TaggedFields(err)
+	}
+	sensitive := hasSensitive(fields)
+	redacted := RedactFields(fields)
+
+	var msg string
+	switch tErr := err.(type) {
+	case Redactable:
+		msg = tErr.RedactedError()
+	case *fieldsError:
+		msg = fieldsMessage(redacted)
+	default:
+		if !sensitive {
+			return err
+		}
+		msg = err.Error()
+	}
+
+	rErr := redactedError{original: err, msg: msg, fields: redacted, Wrapping: Wrapping{err: Unwrap(err)}}
+	if isFielder {
+		return &redactedFielderError{rErr}
+	}
+	return &rErr
+}
+
+// typeName returns the name of the type of err, or of the original error if err is a redacting serializer's stand-in.
+func typeName(err error) string {
+	switch rErr := err.(type) {
+	case *redactedError:
+		err = rErr.original
+	case *redactedFielderError:
+		err = rErr.original
+	}
+	return reflect.TypeOf(err).String()
+}
+
+type redactingSerializer struct {
+	Decorating
+}
+
+func (s *redactingSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	rErr := redact(err)
+	if rErr == err {
+		return s.Serializer.CustomFormat(err, buf)
+	}
+
+	if !s.Serializer.CustomFormat(rErr, buf) {
+		buf.WriteString(rErr.Error())
+	}
+
+	return true
+}
+
+// NewRedactingSerializer decorates a Serializer so Sensitive and Redactable errors have their messages redacted,
+// and the Sensitive fields of all errors their values, see Field.
+// The decorated Serializer receives a stand-in error for those, carrying the redacted message and fields.
+// It should decorate any other decorators, such as that of NewTaggedFieldsSerializer, so they see the stand-ins.
+// Printers built on undecorated Serializers, such as those of String and DetailString, still print the raw messages.
+func NewRedactingSerializer(s Serializer) Serializer {
+	return &redactingSerializer{
+		Decorating: Decorating{Serializer: s},
+	}
+}
+
+var (
+	redactedPrinter         = NewPrinter(func() Serializer { return NewRedactingSerializer(NewColonBasicSerializer()) })
+	redactedDetailedPrinter = NewPrinter(func() Serializer { return NewRedactingSerializer(NewColonDetailedSerializer()) })
+)
+
+// RedactedString serialises an error like String, but with Sensitive and Redactable messages redacted.
+func RedactedString(err error) string {
+	return encodeString(err, redactedPrinter)
+}
+
+// RedactedDetailString serialises an error like DetailString, but with Sensitive and Redactable messages redacted.
+func RedactedDetailString(err error) string {
+	return encodeString(err, redactedDetailedPrinter)
+}
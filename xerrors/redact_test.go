@@ -0,0 +1,68 @@
+package xerrors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func TestRedactedString(t *testing.T) {
+	scenarios := []struct {
+		name             string
+		err              error
+		expectedOut      string
+		expectedRedacted string
+	}{
+		{
+			name:             "notSensitive",
+			err:              xerrors.Wrap("wrapper", xerrors.New("msg"), xerrors.OmitFrame()),
+			expectedOut:      "wrapper: msg",
+			expectedRedacted: "wrapper: msg",
+		},
+		{
+			name:             "sensitive",
+			err:              xerrors.Wrap("wrapper", xerrors.WrapSensitive("token abc", xerrors.New("msg"), xerrors.OmitFrame()), xerrors.OmitFrame()),
+			expectedOut:      "wrapper: token abc: msg",
+			expectedRedacted: "wrapper: [REDACTED]: msg",
+		},
+		{
+			name: "redactable",
+			err: xerrors.WrapRedactable(
+				"no account foo@bar.com with number 1234",
+				[]string{"foo@bar.com", "1234", ""},
+				xerrors.New("msg"),
+				xerrors.OmitFrame(),
+			),
+			expectedOut:      "no account foo@bar.com with number 1234: msg",
+			expectedRedacted: "no account [REDACTED] with number [REDACTED]: msg",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if out := xerrors.String(scenario.err); out != scenario.expectedOut {
+				t.Fatalf("mismatched String output, expected %q got %q", scenario.expectedOut, out)
+			}
+
+			if out := xerrors.RedactedString(scenario.err); out != scenario.expectedRedacted {
+				t.Fatalf("mismatched RedactedString output, expected %q got %q", scenario.expectedRedacted, out)
+			}
+		})
+	}
+}
+
+func TestRedactedDetailString(t *testing.T) {
+	err := xerrors.WrapSensitive("token abc", xerrors.New("msg"))
+
+	out := xerrors.RedactedDetailString(err)
+	if !strings.HasPrefix(out, "[REDACTED](xerrors_test.TestRedactedDetailString:redact_test.go:") {
+		t.Fatalf("expected redacted message followed by its frame, got %q", out)
+	}
+
+	if xerrors.Unwrap(err) == nil || !xerrors.IsFrameError(xerrors.Unwrap(err)) {
+		t.Fatal("expected sensitive error to wrap a frame")
+	}
+}
@@ -0,0 +1,112 @@
+package xerrors
+
+import (
+	"io"
+)
+
+var reverseSeparator = []byte(" <- ")
+
+// reverseColonSerializer is the colonSerializer for Printers created WithReverseOrder.
+// Frames precede the error they belong to in reverse order, so they are held until that error is appended.
+type reverseColonSerializer struct {
+	colonSerializer
+
+	// frame holds the pending frame, if hasFrame is set.
+	frame    []byte
+	hasFrame bool
+}
+
+func (s *reverseColonSerializer) Append(w io.Writer, msg []byte) error {
+	if s.isFrame {
+		s.isFrame = false
+		s.frame = append(s.frame[:0], msg...)
+		s.hasFrame = true
+		return nil
+	}
+
+	if s.firstEntry {
+		s.firstEntry = false
+	} else if _, err := w.Write(reverseSeparator); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	return s.writeFrame(w)
+}
+
+// order has Printers always present errors innermost first, the only order the serializer is meant for.
+func (s *reverseColonSerializer) order(bool) bool {
+	return true
+}
+
+// Flush writes the pending frame, only left if the outermost error is a FrameError.
+func (s *reverseColonSerializer) Flush(w io.Writer) error {
+	return s.writeFrame(w)
+}
+
+func (s *reverseColonSerializer) writeFrame(w io.Writer) error {
+	if !s.hasFrame {
+		return nil
+	}
+	s.hasFrame = false
+
+	if _, err := w.Write(frameOpen); err != nil {
+		return err
+	}
+	if _, err := w.Write(s.frame); err != nil {
+		return err
+	}
+	_, err := w.Write(frameClose)
+	return err
+}
+
+func (s *reverseColonSerializer) Reset() {
+	s.colonSerializer.Reset()
+	s.frame = s.frame[:0]
+	s.hasFrame = false
+}
+
+func newReverseColonSerializer(keepFrames bool) Serializer {
+	return &reverseColonSerializer{
+		colonSerializer: colonSerializer{
+			firstEntry: true,
+			keepFrames: keepFrames,
+			isFrame:    false,
+			formatters: defaultFormatters,
+		},
+	}
+}
+
+// NewColonBasicReverseSerializer is the reverse form of NewColonBasicSerializer, as per Reverse.
+// Messages are appended with ' <- ', the root cause first: 'cause <- wrapping_msg'.
+// Printers present it errors innermost first, whether created WithReverseOrder or not.
+func NewColonBasicReverseSerializer() Serializer {
+	return newReverseColonSerializer(false)
+}
+
+// NewColonDetailedReverseSerializer is the reverse form of NewColonDetailedSerializer, as per Reverse.
+// Messages are appended with ' <- ', the root cause first, and frames follow the message they belong to as usual:
+// 'cause <- wrapping_msg(frame)'.
+// Printers present it errors innermost first, whether created WithReverseOrder or not.
+func NewColonDetailedReverseSerializer() Serializer {
+	return newReverseColonSerializer(true)
+}
+
+var (
+	reversePrinter         = NewPrinter(NewColonBasicReverseSerializer)
+	reverseDetailedPrinter = NewPrinter(NewColonDetailedReverseSerializer)
+)
+
+// ReverseString serialises an error like String, but with the root cause first: 'cause <- wrapping_msg'.
+func ReverseString(err error) string {
+	return encodeString(err, reversePrinter)
+}
+
+// ReverseDetailString serialises an error like DetailString, but with the root cause first:
+// 'cause <- wrapping_msg(frame)'.
+func ReverseDetailString(err error) string {
+	return encodeString(err, reverseDetailedPrinter)
+}
@@ -0,0 +1,191 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+// beware, the lines in this method are required to test frame wrapping logic
+// any formatting changes will break tests and will require changes to the expected line constants
+func TestReverseString(t *testing.T) {
+	scenarios := []struct {
+		name              string
+		err               error
+		expectedBasicOut  string
+		expectedDetailOut string
+	}{
+		{
+			name:              "nonWrapped",
+			err:               xerrors.New("msg"),
+			expectedBasicOut:  "msg",
+			expectedDetailOut: "msg",
+		},
+		{
+			name: "doubleWrapped",
+			err: xerrors.Wrap(
+				"start server",
+				xerrors.Wrap("load config", errors.New("open foo: permission denied")),
+			),
+			expectedBasicOut:  "open foo: permission denied <- load config <- start server",
+			expectedDetailOut: "open foo: permission denied <- load config(xerrors_test.TestReverseString:reverse_test.go:30) <- start server(xerrors_test.TestReverseString:reverse_test.go:28)",
+		},
+		{
+			name:              "cycle",
+			err:               xerrors.Wrap("wrapper", selfError{}, xerrors.OmitFrame()),
+			expectedBasicOut:  "...(cycle) <- self <- wrapper",
+			expectedDetailOut: "...(cycle) <- self <- wrapper",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if out := xerrors.ReverseString(scenario.err); out != scenario.expectedBasicOut {
+				t.Fatalf("mismatched ReverseString output, expected %q got %q", scenario.expectedBasicOut, out)
+			}
+			if out := xerrors.ReverseDetailString(scenario.err); out != scenario.expectedDetailOut {
+				t.Fatalf("mismatched ReverseDetailString output, expected %q got %q", scenario.expectedDetailOut, out)
+			}
+		})
+	}
+}
+
+func TestWithReverseOrder(t *testing.T) {
+	err := xerrors.Wrap("outer", xerrors.Wrap("middle", xerrors.New("inner"), xerrors.OmitFrame()), xerrors.OmitFrame())
+
+	scenarios := []struct {
+		name        string
+		factory     func() xerrors.Serializer
+		opts        []xerrors.PrinterOptionFunc
+		expectedOut string
+	}{
+		{
+			name:        "colonBasic",
+			factory:     xerrors.NewColonBasicSerializer,
+			expectedOut: "inner: middle: outer",
+		},
+		{
+			name:        "tree",
+			factory:     xerrors.NewTreeSerializer,
+			expectedOut: xerrors.TreeString(err),
+		},
+		{
+			name:        "maxDepth",
+			factory:     xerrors.NewColonBasicReverseSerializer,
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxDepth(2)},
+			expectedOut: "...(1 more) <- middle <- outer",
+		},
+		{
+			name:        "maxLength",
+			factory:     xerrors.NewColonBasicReverseSerializer,
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxLength(8)},
+			expectedOut: "inner <- mid...",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			opts := append([]xerrors.PrinterOptionFunc{xerrors.WithReverseOrder()}, scenario.opts...)
+			printer := xerrors.NewPrinter(scenario.factory, opts...)
+
+			// twice, to validate the pooled state is reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if err := printer.Write(&buf, err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestReverse(t *testing.T) {
+	err := xerrors.Wrap("outer", xerrors.Wrap("middle", xerrors.New("inner")))
+
+	scenarios := []struct {
+		name    string
+		factory func() xerrors.Serializer
+		opts    []xerrors.PrinterOptionFunc
+	}{
+		{
+			name:    "colonDetailedReverse",
+			factory: xerrors.NewColonDetailedReverseSerializer,
+		},
+		{
+			name:    "colonBasic",
+			factory: xerrors.NewColonBasicSerializer,
+			opts:    []xerrors.PrinterOptionFunc{xerrors.WithMaxLength(8)},
+		},
+		{
+			name:    "maxDepth",
+			factory: xerrors.NewColonBasicReverseSerializer,
+			opts:    []xerrors.PrinterOptionFunc{xerrors.WithMaxDepth(2)},
+		},
+		{
+			name:    "tree",
+			factory: xerrors.NewTreeSerializer,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			expected := bytes.Buffer{}
+			opts := append([]xerrors.PrinterOptionFunc{xerrors.WithReverseOrder()}, scenario.opts...)
+			if err := xerrors.NewPrinter(scenario.factory, opts...).Write(&expected, err); err != nil {
+				t.Fatalf("error serialising error: %s", err)
+			}
+
+			buf := bytes.Buffer{}
+			printer := xerrors.NewPrinter(func() xerrors.Serializer { return xerrors.Reverse(scenario.factory()) }, scenario.opts...)
+			if err := printer.Write(&buf, err); err != nil {
+				t.Fatalf("error serialising error: %s", err)
+			}
+
+			if buf.String() != expected.String() {
+				t.Fatalf("mismatched output, expected %q as WithReverseOrder got %q", expected.String(), buf.String())
+			}
+		})
+	}
+}
+
+func TestNewColonBasicReverseSerializer_order(t *testing.T) {
+	err := xerrors.Wrap("outer", xerrors.New("inner"), xerrors.OmitFrame())
+
+	buf := bytes.Buffer{}
+	if err := xerrors.NewPrinter(xerrors.NewColonBasicReverseSerializer).Write(&buf, err); err != nil {
+		t.Fatalf("error serialising error: %s", err)
+	}
+
+	if expected := "inner <- outer"; buf.String() != expected {
+		t.Fatalf("mismatched output, expected %q got %q", expected, buf.String())
+	}
+}
+
+func BenchmarkReverseString(b *testing.B) {
+	scenarios := encodeScenarios()
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		b.ResetTimer()
+
+		b.Run(scenario.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// not bothering to check the output, already covered in the tests
+				_ = xerrors.ReverseString(scenario.err)
+			}
+		})
+	}
+}
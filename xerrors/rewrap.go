@@ -0,0 +1,218 @@
+package xerrors
+
+import (
+	"reflect"
+)
+
+// Rewrapper is a Wrapper that can produce a copy of itself wrapping a different error.
+// It is what allows chains to be rebuilt, see Map.
+type Rewrapper interface {
+	Wrapper
+
+	// Rewrap returns a copy of the error, identical other than wrapping the provided error.
+	// It must not modify the original error.
+	Rewrap(error) error
+}
+
+var wrappingType = reflect.TypeOf(Wrapping{})
+
+// Rewrap returns a copy of err wrapping inner instead of its current wrapped error.
+// Errors implementing Rewrapper are copied through it.
+// Otherwise, errors that embed Wrapping (struct types or pointers to them) are copied via reflection.
+// It returns false if err is neither of these.
+func Rewrap(err, inner error) (error, bool) {
+	if rErr, ok := err.(Rewrapper); ok {
+		return rErr.Rewrap(inner), true
+	}
+
+	v := reflect.ValueOf(err)
+	if !v.IsValid() {
+		return nil, false
+	}
+
+	isPtr := v.Kind() == reflect.Ptr
+	if isPtr {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	field, ok := v.Type().FieldByName(wrappingType.Name())
+	if !ok || !field.Anonymous || field.Type != wrappingType || len(field.Index) != 1 {
+		return nil, false
+	}
+
+	cp := reflect.New(v.Type())
+	cp.Elem().Set(v)
+	cp.Elem().Field(field.Index[0]).Set(reflect.ValueOf(Wrapping{err: inner}))
+
+	if isPtr {
+		return cp.Interface().(error), true
+	}
+	return cp.Elem().Interface().(error), true
+}
+
+func (err *wrappingError) Rewrap(inner error) error {
+	return &wrappingError{
+		msg:      err.msg,
+		Wrapping: Wrapping{err: inner},
+	}
+}
+
+func (err *frameError) Rewrap(inner error) error {
+	return &frameError{
+		frames:   err.frames,
+		Wrapping: Wrapping{err: inner},
+	}
+}
+
+func (err *timedFrameError) Rewrap(inner error) error {
+	return &timedFrameError{
+		frameError: frameError{frames: err.frames, Wrapping: Wrapping{err: inner}},
+		time:       err.time,
+		goroutine:  err.goroutine,
+	}
+}
+
+func (err *spawnFrameError) Rewrap(inner error) error {
+	return &spawnFrameError{frameError{frames: err.frames, Wrapping: Wrapping{err: inner}}}
+}
+
+func (err *staticFrameError) Rewrap(inner error) error {
+	return &staticFrameError{
+		function: err.function,
+		file:     err.file,
+		line:     err.line,
+		Wrapping: Wrapping{err: inner},
+	}
+}
+
+func (err *staticTimedFrameError) Rewrap(inner error) error {
+	return &staticTimedFrameError{
+		staticFrameError: staticFrameError{function: err.function, file: err.file, line: err.line, Wrapping: Wrapping{err: inner}},
+		time:             err.time,
+		goroutine:        err.goroutine,
+	}
+}
+
+func (err *staticSpawnFrameError) Rewrap(inner error) error {
+	return &staticSpawnFrameError{
+		staticFrameError{function: err.function, file: err.file, line: err.line, Wrapping: Wrapping{err: inner}},
+	}
+}
+
+var (
+	_ Rewrapper = (*wrappingError)(nil)
+	_ Rewrapper = (*frameError)(nil)
+	_ Rewrapper = (*timedFrameError)(nil)
+	_ Rewrapper = (*spawnFrameError)(nil)
+	_ Rewrapper = (*staticFrameError)(nil)
+	_ Rewrapper = (*staticTimedFrameError)(nil)
+	_ Rewrapper = (*staticSpawnFrameError)(nil)
+)
+
+// identical reports if two errors are the same value, without panicking on uncomparable types.
+// Types such as structs are comparable, yet comparing them panics if they hold uncomparable values in interfaces,
+// in which case they are not identical.
+func identical(err1, err2 error) (same bool) {
+	t := reflect.TypeOf(err1)
+	if t != reflect.TypeOf(err2) {
+		return false
+	}
+	if t == nil || t.Kind() == reflect.Pointer {
+		return err1 == err2
+	}
+	if !t.Comparable() {
+		return false
+	}
+
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return err1 == err2
+}
+
+// Map rebuilds the chain of err, replacing each error by the output of f.
+// f receives each error in the chain (outermost first) and returns its replacement, or nil to drop it.
+// Returning the error unmodified keeps it, only rewrapping it if an error beneath it has changed.
+// The wrapped error of a replacement is irrelevant, it is rewrapped over the rebuilt remainder of the chain.
+//
+// Replacements are rewrapped as per Rewrap.
+// Those which cannot be are substituted by a string error with the same message, losing their type.
+// The original chain is never modified, and the unchanged innermost part of it is shared with the output.
+//
+// The chain is walked as All does. Errors it does not reach, those beyond MaxDepth or past a cycle, are not passed to f
+// and are kept unmodified beneath the rebuilt chain. Errors wrapping multiple errors are mapped as a whole.
+func Map(err error, f func(error) error) error {
+	var layers []error
+	w := newWalker(false)
+	w.walk(err, 0, func(_ int, err error) bool {
+		layers = append(layers, err)
+		_, isMulti := err.(multiWrapper)
+		return !isMulti
+	})
+
+	var inner error
+	if w.truncated() && len(layers) != 0 {
+		inner = Unwrap(layers[len(layers)-1])
+	}
+	changed := false
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+
+		mapped := f(layer)
+		if mapped == nil {
+			changed = true
+			continue
+		}
+
+		if !changed && identical(mapped, layer) {
+			inner = layer
+			continue
+		}
+
+		changed = true
+
+		if identical(Unwrap(mapped), inner) {
+			inner = mapped
+			continue
+		}
+
+		if rewrapped, ok := Rewrap(mapped, inner); ok {
+			inner = rewrapped
+			continue
+		}
+
+		inner = &wrappingError{
+			msg:      mapped.Error(),
+			Wrapping: Wrapping{err: inner},
+		}
+	}
+
+	return inner
+}
+
+// Filter rebuilds the chain of err, keeping only the errors for which keep returns true.
+// It is a special case of Map, with the same rewrapping behaviour.
+func Filter(err error, keep func(error) bool) error {
+	return Map(err, func(err error) error {
+		if !keep(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// StripFrames rebuilds the chain of err without any FrameError.
+// It is useful before sending errors to other processes, where frames are meaningless, or see StaticFrames.
+func StripFrames(err error) error {
+	return Filter(err, isNotFrameError)
+}
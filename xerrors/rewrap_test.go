@@ -0,0 +1,237 @@
+package xerrors_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+type publicError struct {
+	xerrors.Wrapping
+}
+
+func (*publicError) Error() string { return "public" }
+
+type unwrappableError struct {
+	msg string
+}
+
+func (err unwrappableError) Error() string { return err.msg }
+
+func (unwrappableError) Unwrap() error { return nil }
+
+func TestRewrap(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut error
+		expectedOk  bool
+	}{
+		{
+			name:        "nil",
+			err:         nil,
+			expectedOut: nil,
+			expectedOk:  false,
+		},
+		{
+			name:        "wrappingError",
+			err:         xerrors.Wrap("msg", nil, xerrors.OmitFrame()),
+			expectedOut: xerrors.Wrap("msg", xerrors.New("inner"), xerrors.OmitFrame()),
+			expectedOk:  true,
+		},
+		{
+			name:        "embeddedValue",
+			err:         fooError{},
+			expectedOut: fooError{xerrors.NewWrapping(xerrors.New("inner"), xerrors.OmitFrame())},
+			expectedOk:  true,
+		},
+		{
+			name:        "embeddedPointer",
+			err:         &publicError{},
+			expectedOut: &publicError{xerrors.NewWrapping(xerrors.New("inner"), xerrors.OmitFrame())},
+			expectedOk:  true,
+		},
+		{
+			name:        "notEmbedded",
+			err:         unwrappableError{"msg"},
+			expectedOut: nil,
+			expectedOk:  false,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			out, ok := xerrors.Rewrap(scenario.err, xerrors.New("inner"))
+			if ok != scenario.expectedOk {
+				t.Fatalf("mismatched ok, expected %t got %t", scenario.expectedOk, ok)
+			}
+			if !reflect.DeepEqual(out, scenario.expectedOut) {
+				t.Fatalf("mismatched outputs, expected %q got %q", scenario.expectedOut, out)
+			}
+		})
+	}
+}
+
+func TestRewrap_doesNotModifyOriginal(t *testing.T) {
+	original := &publicError{xerrors.NewWrapping(nil, xerrors.OmitFrame())}
+
+	if _, ok := xerrors.Rewrap(original, xerrors.New("inner")); !ok {
+		t.Fatal("expected error to be rewrapped")
+	}
+
+	if original.Unwrap() != nil {
+		t.Fatal("Rewrap must not modify the original error")
+	}
+}
+
+func TestMap(t *testing.T) {
+	toPublic := func(err error) error {
+		if _, ok := err.(Fooer); ok {
+			return &publicError{}
+		}
+		return err
+	}
+
+	scenarios := []struct {
+		name        string
+		err         error
+		f           func(error) error
+		expectedOut error
+	}{
+		{
+			name:        "nil",
+			err:         nil,
+			f:           func(error) error { panic("not to be called") },
+			expectedOut: nil,
+		},
+		{
+			name:        "unchanged",
+			err:         xerrors.Wrap("wrapper", xerrors.New("msg"), xerrors.OmitFrame()),
+			f:           func(err error) error { return err },
+			expectedOut: xerrors.Wrap("wrapper", xerrors.New("msg"), xerrors.OmitFrame()),
+		},
+		{
+			name:        "replaced",
+			err:         xerrors.Wrap("wrapper", fooError{xerrors.NewWrapping(xerrors.New("msg"), xerrors.OmitFrame())}, xerrors.OmitFrame()),
+			f:           toPublic,
+			expectedOut: xerrors.Wrap("wrapper", &publicError{xerrors.NewWrapping(xerrors.New("msg"), xerrors.OmitFrame())}, xerrors.OmitFrame()),
+		},
+		{
+			name: "notRewrappableUnchanged",
+			err:  unwrappableError{"outer"},
+			f: func(err error) error {
+				if _, ok := err.(unwrappableError); ok {
+					return err
+				}
+				return nil
+			},
+			expectedOut: unwrappableError{"outer"},
+		},
+		{
+			name: "notRewrappableOverChanged",
+			err:  xerrors.Wrap("wrapper", fooError{}, xerrors.OmitFrame()),
+			f: func(err error) error {
+				if _, ok := err.(Fooer); ok {
+					return &publicError{}
+				}
+				return unwrappableError{"replaced"}
+			},
+			expectedOut: xerrors.Wrap("replaced", &publicError{}, xerrors.OmitFrame()),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			out := xerrors.Map(scenario.err, scenario.f)
+			if !reflect.DeepEqual(out, scenario.expectedOut) {
+				t.Fatalf("mismatched outputs, expected %q got %q", xerrors.String(scenario.expectedOut), xerrors.String(out))
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	err := xerrors.Wrap("outer", fooError{xerrors.NewWrapping(xerrors.New("msg"), xerrors.OmitFrame())}, xerrors.OmitFrame())
+
+	out := xerrors.Filter(err, func(err error) bool { _, ok := err.(Fooer); return !ok })
+
+	if expectedOut := xerrors.Wrap("outer", xerrors.New("msg"), xerrors.OmitFrame()); !reflect.DeepEqual(out, expectedOut) {
+		t.Fatalf("mismatched outputs, expected %q got %q", xerrors.String(expectedOut), xerrors.String(out))
+	}
+}
+
+func TestStripFrames(t *testing.T) {
+	err := xerrors.Wrap("outer", xerrors.Wrap("inner", xerrors.New("msg")))
+
+	out := xerrors.StripFrames(err)
+
+	expectedOut := xerrors.Wrap("outer", xerrors.Wrap("inner", xerrors.New("msg"), xerrors.OmitFrame()), xerrors.OmitFrame())
+	if !reflect.DeepEqual(out, expectedOut) {
+		t.Fatalf("mismatched outputs, expected %q got %q", xerrors.DetailString(expectedOut), xerrors.DetailString(out))
+	}
+
+	if xerrors.LastFrameError(err) == nil {
+		t.Fatal("StripFrames must not modify the original error")
+	}
+}
+
+func TestStripFrames_cycles(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut string
+	}{
+		{
+			name:        "self",
+			err:         xerrors.Wrap("wrapper", selfError{}),
+			expectedOut: "wrapper: self: ...(cycle)",
+		},
+		{
+			name:        "loop",
+			err:         xerrors.Wrap("wrapper", newLoopError()),
+			expectedOut: "wrapper: a: b: c: b: ...(cycle)",
+		},
+		{
+			name:        "multiLoop",
+			err:         xerrors.Wrap("wrapper", newLoopJoinError()),
+			expectedOut: "wrapper: join: left: right: ...(cycle)",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			out := xerrors.StripFrames(scenario.err)
+
+			if xerrors.LastFrameError(out) != nil {
+				t.Fatalf("expected no frames, got %q", xerrors.DetailString(out))
+			}
+			if s := xerrors.String(out); s != scenario.expectedOut {
+				t.Fatalf("mismatched outputs, expected %q got %q", scenario.expectedOut, s)
+			}
+		})
+	}
+}
+
+func TestMap_maxDepth(t *testing.T) {
+	err := deepError(xerrors.MaxDepth() + 10)
+
+	var calls int
+	out := xerrors.Map(err, func(err error) error {
+		calls++
+		return err
+	})
+
+	if calls != xerrors.MaxDepth() {
+		t.Fatalf("expected %d calls, got %d", xerrors.MaxDepth(), calls)
+	}
+	if out != err {
+		t.Fatal("expected the unmodified chain")
+	}
+}
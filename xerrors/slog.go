@@ -0,0 +1,25 @@
+package xerrors
+
+import (
+	"log/slog"
+)
+
+// SlogValue represents an error as a log/slog group value.
+// The group holds the error's RedactedString under 'msg', followed by its Fields with their native types.
+// As logs are seldom private, Sensitive and Redactable messages and the values of Sensitive fields are redacted.
+func SlogValue(err error) slog.Value {
+	fields := RedactFields(Fields(err))
+
+	attrs := make([]slog.Attr, 0, len(fields)+1)
+	attrs = append(attrs, slog.String("msg", RedactedString(err)))
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// SlogAttr is a helper for slog.Attr with SlogValue.
+func SlogAttr(key string, err error) slog.Attr {
+	return slog.Attr{Key: key, Value: SlogValue(err)}
+}
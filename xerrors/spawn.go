@@ -0,0 +1,94 @@
+package xerrors
+
+// SpawnFrameError is a FrameError for the location a goroutine was launched from.
+// They are produced by Go and SpawnSite, the detailed serializers present them as 'spawned at' the location.
+type SpawnFrameError interface {
+	FrameError
+	isSpawnFrame()
+}
+
+// IsSpawnFrameError is a helper for type casting to SpawnFrameError
+func IsSpawnFrameError(err error) bool {
+	_, ok := err.(SpawnFrameError)
+	return ok
+}
+
+// LastSpawnFrameError is a helper for Last with IsSpawnFrameError, returning a typed SpawnFrameError
+func LastSpawnFrameError(err error) SpawnFrameError {
+	err = Last(err, IsSpawnFrameError)
+	if err == nil {
+		return nil
+	}
+	return err.(SpawnFrameError)
+}
+
+type spawnFrameError struct {
+	frameError
+}
+
+func (err *spawnFrameError) Error() string {
+	return "spawned at " + err.frameError.Error()
+}
+
+func (*spawnFrameError) isSpawnFrame() {}
+
+// NewStaticSpawnFrame is NewStaticFrame for a SpawnFrameError.
+func NewStaticSpawnFrame(function, file string, line int, err error) error {
+	return &staticSpawnFrameError{
+		staticFrameError{function: function, file: file, line: line, Wrapping: Wrapping{err: err}},
+	}
+}
+
+type staticSpawnFrameError struct {
+	staticFrameError
+}
+
+func (err *staticSpawnFrameError) Error() string {
+	return "spawned at " + err.staticFrameError.Error()
+}
+
+func (*staticSpawnFrameError) isSpawnFrame() {}
+
+var (
+	_ SpawnFrameError = (*spawnFrameError)(nil)
+	_ SpawnFrameError = (*staticSpawnFrameError)(nil)
+)
+
+// SpawnSite is the location a goroutine is launched from, to be attached to the errors coming out of it.
+type SpawnSite struct {
+	frames [3]uintptr
+}
+
+// NewSpawnSite records the calling location, skipping helpers as per Helper.
+// It is to be called before launching a goroutine, whose errors are then passed to Wrap.
+// Sites are recorded regardless of the FramePolicy, as the goroutine's own frames can't reveal them.
+func NewSpawnSite() SpawnSite {
+	return newSpawnSite(2)
+}
+
+func newSpawnSite(skip uint8) SpawnSite {
+	skip += helperFrames(skip)
+	return SpawnSite{frames: caller(skip)}
+}
+
+// Wrap wraps err in a SpawnFrameError of the site. A nil err is returned as is.
+func (s SpawnSite) Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &spawnFrameError{frameError{frames: s.frames, Wrapping: Wrapping{err: err}}}
+}
+
+// Go runs f in a new goroutine, sending its error wrapped in a SpawnFrameError of the calling location.
+// The channel receives the single error, nil if f succeeded, and is then closed.
+func Go(f func() error) <-chan error {
+	site := newSpawnSite(2)
+
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		errs <- site.Wrap(f())
+	}()
+
+	return errs
+}
@@ -0,0 +1,93 @@
+package xerrors_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func goHelper(f func() error) <-chan error {
+	xerrors.Helper()
+	return xerrors.Go(f)
+}
+
+func TestGo(t *testing.T) {
+	err := <-xerrors.Go(func() error {
+		return xerrors.Wrap("worker", errors.New("cause"))
+	})
+
+	spawnErr := xerrors.LastSpawnFrameError(err)
+	if spawnErr == nil {
+		t.Fatalf("expected a spawn frame, got %s", xerrors.DetailString(err))
+	}
+	if function, _, _ := spawnErr.FrameLocation(); function != testPackage+".TestGo" {
+		t.Fatalf("mismatched function, expected %q got %q", testPackage+".TestGo", function)
+	}
+
+	expected := regexp.MustCompile(`^\(spawned at xerrors_test\.TestGo:spawn_test\.go:\d+\): ` +
+		`worker\(xerrors_test\.TestGo\.func1:spawn_test\.go:\d+\): cause$`)
+	if out := xerrors.DetailString(err); !expected.MatchString(out) {
+		t.Fatalf("mismatched DetailString, expected to match %q got %q", expected, out)
+	}
+
+	if expected := "worker: cause"; xerrors.String(err) != expected {
+		t.Fatalf("mismatched String, expected %q got %q", expected, xerrors.String(err))
+	}
+}
+
+func TestGo_success(t *testing.T) {
+	errs := xerrors.Go(func() error { return nil })
+
+	if err := <-errs; err != nil {
+		t.Fatalf("unexpected error: %s", xerrors.DetailString(err))
+	}
+	if _, ok := <-errs; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+}
+
+func TestGo_helper(t *testing.T) {
+	err := <-goHelper(func() error { return errors.New("cause") })
+
+	if function, _, _ := xerrors.LastSpawnFrameError(err).FrameLocation(); function != testPackage+".TestGo_helper" {
+		t.Fatalf("mismatched function, expected %q got %q", testPackage+".TestGo_helper", function)
+	}
+}
+
+func TestSpawnSite(t *testing.T) {
+	site := xerrors.NewSpawnSite()
+
+	if site.Wrap(nil) != nil {
+		t.Fatal("expected a nil error to remain nil")
+	}
+
+	results := make(chan error, 1)
+	go func() {
+		results <- site.Wrap(errors.New("cause"))
+	}()
+	err := xerrors.Wrap("outer", <-results, xerrors.OmitFrame())
+
+	expected := regexp.MustCompile(`^outer\(spawned at xerrors_test\.TestSpawnSite:spawn_test\.go:\d+\): cause$`)
+	if out := xerrors.DetailString(err); !expected.MatchString(out) {
+		t.Fatalf("mismatched DetailString, expected to match %q got %q", expected, out)
+	}
+
+	if !xerrors.IsFrameError(xerrors.Unwrap(err)) {
+		t.Fatal("expected the spawn frame to be a FrameError")
+	}
+	if xerrors.LastSpawnFrameError(xerrors.Wrap("msg", errors.New("cause"))) != nil {
+		t.Fatal("expected ordinary frames not to be spawn frames")
+	}
+
+	mapped := xerrors.Map(err, func(err error) error {
+		if err.Error() == "cause" {
+			return errors.New("replaced")
+		}
+		return err
+	})
+	if xerrors.LastSpawnFrameError(mapped) == nil {
+		t.Fatalf("expected the spawn frame to survive rewrapping, got %s", xerrors.DetailString(mapped))
+	}
+}
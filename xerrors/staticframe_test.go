@@ -0,0 +1,99 @@
+package xerrors_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func TestNewStaticFrame(t *testing.T) {
+	cause := xerrors.New("cause")
+	err := xerrors.NewStaticFrame("pkg.Function", "/src/pkg/file.go", 12, cause)
+
+	function, file, line := err.(xerrors.FrameError).FrameLocation()
+	if function != "pkg.Function" || file != "/src/pkg/file.go" || line != 12 {
+		t.Fatalf("mismatched location, got %q %q %d", function, file, line)
+	}
+
+	if xerrors.Unwrap(err) != cause {
+		t.Fatal("expected the static frame to wrap the cause")
+	}
+
+	if expected := "pkg.Function:/src/pkg/file.go:12"; err.Error() != expected {
+		t.Fatalf("mismatched Error, expected %q got %q", expected, err.Error())
+	}
+
+	wrapped := xerrors.Wrap("outer", err, xerrors.OmitFrame())
+	if expected := "outer(pkg.Function:file.go:12): cause"; xerrors.DetailString(wrapped) != expected {
+		t.Fatalf("mismatched DetailString, expected %q got %q", expected, xerrors.DetailString(wrapped))
+	}
+}
+
+func TestStaticFrames(t *testing.T) {
+	err := xerrors.Wrap("outer", xerrors.Wrap("inner", xerrors.New("cause")))
+
+	static := xerrors.StaticFrames(err)
+
+	if xerrors.DetailString(static) != xerrors.DetailString(err) {
+		t.Fatalf("mismatched DetailString, expected %q got %q", xerrors.DetailString(err), xerrors.DetailString(static))
+	}
+
+	if !xerrors.Similar(static, err) {
+		t.Fatal("expected the static chain to be similar to the original")
+	}
+
+	if expected, got := xerrors.LastFrameError(err).Error(), xerrors.LastFrameError(static).Error(); got != expected {
+		t.Fatalf("mismatched frame Error, expected %q got %q", expected, got)
+	}
+
+	staticType := reflect.TypeOf(xerrors.NewStaticFrame("", "", 0, nil))
+	for layer := range xerrors.All(static) {
+		if xerrors.IsFrameError(layer) && reflect.TypeOf(layer) != staticType {
+			t.Fatalf("expected a static frame, got %T", layer)
+		}
+	}
+
+	if again := xerrors.StaticFrames(static); again != static {
+		t.Fatal("expected a chain of static frames to be unchanged")
+	}
+}
+
+func TestStaticFrames_timedAndSpawn(t *testing.T) {
+	inner := xerrors.Wrap("inner", errors.New("cause"), xerrors.RecordTime())
+	err := xerrors.Wrap("outer", xerrors.NewSpawnSite().Wrap(inner), xerrors.OmitFrame())
+
+	static := xerrors.StaticFrames(err)
+
+	if xerrors.DetailString(static) != xerrors.DetailString(err) {
+		t.Fatalf("mismatched DetailString, expected %q got %q", xerrors.DetailString(err), xerrors.DetailString(static))
+	}
+
+	timedErr, staticTimedErr := xerrors.LastTimedFrameError(err), xerrors.LastTimedFrameError(static)
+	if staticTimedErr == nil {
+		t.Fatalf("expected a timed frame, got %s", xerrors.DetailString(static))
+	}
+	if !staticTimedErr.FrameTime().Equal(timedErr.FrameTime()) || staticTimedErr.FrameGoroutine() != timedErr.FrameGoroutine() {
+		t.Fatalf("mismatched timed frame, expected %s in %d got %s in %d",
+			timedErr.FrameTime(), timedErr.FrameGoroutine(), staticTimedErr.FrameTime(), staticTimedErr.FrameGoroutine())
+	}
+
+	if xerrors.LastSpawnFrameError(static) == nil {
+		t.Fatalf("expected a spawn frame, got %s", xerrors.DetailString(static))
+	}
+
+	if again := xerrors.StaticFrames(static); again != static {
+		t.Fatal("expected a chain of static frames to be unchanged")
+	}
+
+	mapped := xerrors.Map(static, func(err error) error {
+		if err.Error() == "cause" {
+			return errors.New("replaced")
+		}
+		return err
+	})
+	if xerrors.LastTimedFrameError(mapped) == nil || xerrors.LastSpawnFrameError(mapped) == nil {
+		t.Fatalf("expected the static frames to survive rewrapping, got %s", xerrors.DetailString(mapped))
+	}
+}
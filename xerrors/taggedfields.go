@@ -0,0 +1,151 @@
+package xerrors
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// FieldTag is the struct tag key used by TaggedFields.
+// Its value is the field name, optionally followed by the comma separated options 'omitempty' and 'redact'.
+// Fields tagged 'redact' are Sensitive, see Field.
+// An empty name stands for the Go field name, a name of '-' excludes the field.
+const FieldTag = "xerr"
+
+var (
+	taggedFieldsOpen  = []byte(" [")
+	taggedFieldsClose = []byte("]")
+)
+
+type taggedField struct {
+	index     []int
+	name      string
+	omitEmpty bool
+	redact    bool
+}
+
+// taggedFieldsCache holds the []taggedField of each reflect.Type seen by TaggedFields.
+var taggedFieldsCache sync.Map
+
+func taggedFieldsOf(t reflect.Type) []taggedField {
+	if cached, ok := taggedFieldsCache.Load(t); ok {
+		return cached.([]taggedField)
+	}
+
+	var fields []taggedField
+	for _, sf := range reflect.VisibleFields(t) {
+		tag, ok := sf.Tag.Lookup(FieldTag)
+		if !ok || !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		field := taggedField{index: sf.Index, name: name}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "redact":
+				field.redact = true
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	cached, _ := taggedFieldsCache.LoadOrStore(t, fields)
+	return cached.([]taggedField)
+}
+
+// TaggedFields returns the fields of an error's struct type (or pointer to one) tagged with FieldTag.
+// Only exported fields are considered, including those promoted from embedded structs.
+// Fields tagged 'omitempty' are omitted if zero, those tagged 'redact' are Sensitive.
+// The type's metadata is cached, so it is only reflected over once.
+//
+// It is used by Fields for errors that do not implement Fielder, and by NewTaggedFieldsSerializer.
+func TaggedFields(err error) []Field {
+	if rErr, ok := err.(*redactedError); ok {
+		return rErr.fields
+	}
+
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []Field
+	for _, tf := range taggedFieldsOf(v.Type()) {
+		fv, fErr := v.FieldByIndexErr(tf.index)
+		if fErr != nil || !fv.CanInterface() || (tf.omitEmpty && fv.IsZero()) {
+			continue
+		}
+
+		fields = append(fields, Field{Key: tf.name, Value: fv.Interface(), Sensitive: tf.redact})
+	}
+
+	return fields
+}
+
+// fieldsOf returns the fields of a Fielder, or TaggedFields otherwise.
+func fieldsOf(err error) []Field {
+	if fErr, ok := err.(Fielder); ok {
+		return fErr.Fields()
+	}
+	return TaggedFields(err)
+}
+
+type taggedFieldsSerializer struct {
+	Decorating
+}
+
+func (s *taggedFieldsSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	var fields []Field
+	if !IsFielder(err) {
+		fields = TaggedFields(err)
+	}
+
+	ok := s.Serializer.CustomFormat(err, buf)
+	if len(fields) == 0 {
+		return ok
+	}
+
+	if !ok {
+		buf.WriteString(err.Error())
+	}
+
+	buf.Write(taggedFieldsOpen)
+	for i, field := range fields {
+		if i != 0 {
+			buf.WriteByte(' ')
+		}
+		writeLogfmtKey(buf, field.Key)
+		buf.WriteByte('=')
+		writeLogfmtFieldValue(buf, field.Value)
+	}
+	buf.Write(taggedFieldsClose)
+
+	return true
+}
+
+// NewTaggedFieldsSerializer decorates a Serializer so errors with TaggedFields have them appended to their message,
+// in logfmt form between square brackets: 'msg [key=value key2=value2]'.
+// Fielder errors are not modified, their fields are expected to be part of their message already.
+func NewTaggedFieldsSerializer(s Serializer) Serializer {
+	return &taggedFieldsSerializer{
+		Decorating: Decorating{Serializer: s},
+	}
+}
@@ -0,0 +1,145 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+type tableInfo struct {
+	Table string `xerr:"table,omitempty"`
+}
+
+type notFoundError struct {
+	ID       int    `xerr:"id"`
+	Owner    string `xerr:"owner,omitempty"`
+	Token    string `xerr:"token,redact"`
+	Ignored  string `xerr:"-"`
+	Untagged string
+	Retries  int `xerr:",omitempty"`
+	internal string
+	tableInfo
+	xerrors.Wrapping
+}
+
+func (*notFoundError) Error() string { return "not found" }
+
+func TestTaggedFields(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		err            error
+		expectedFields []xerrors.Field
+	}{
+		{
+			name:           "nil",
+			err:            nil,
+			expectedFields: nil,
+		},
+		{
+			name:           "untyped",
+			err:            xerrors.New("msg"),
+			expectedFields: nil,
+		},
+		{
+			name: "omitted",
+			err:  &notFoundError{ID: 42, Token: "secret", Ignored: "a", Untagged: "b", internal: "c"},
+			expectedFields: []xerrors.Field{
+				{Key: "id", Value: 42},
+				{Key: "token", Value: "secret", Sensitive: true},
+			},
+		},
+		{
+			name: "full",
+			err:  &notFoundError{ID: 42, Owner: "foo", Retries: 3, tableInfo: tableInfo{Table: "users"}},
+			expectedFields: []xerrors.Field{
+				{Key: "id", Value: 42},
+				{Key: "owner", Value: "foo"},
+				{Key: "token", Value: "", Sensitive: true},
+				{Key: "Retries", Value: 3},
+				{Key: "table", Value: "users"},
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			// twice, to validate the cached metadata
+			for i := 0; i < 2; i++ {
+				if fields := xerrors.TaggedFields(scenario.err); !reflect.DeepEqual(fields, scenario.expectedFields) {
+					t.Fatalf("mismatched fields, expected %v got %v", scenario.expectedFields, fields)
+				}
+			}
+		})
+	}
+}
+
+func TestFields_tagged(t *testing.T) {
+	err := xerrors.WithFields(&notFoundError{ID: 42}, xerrors.Field{Key: "id", Value: 1})
+
+	expectedFields := []xerrors.Field{{Key: "id", Value: 1}, {Key: "token", Value: "", Sensitive: true}}
+	if fields := xerrors.Fields(err); !reflect.DeepEqual(fields, expectedFields) {
+		t.Fatalf("mismatched fields, expected %v got %v", expectedFields, fields)
+	}
+}
+
+func TestTaggedFieldsSerializers(t *testing.T) {
+	err := xerrors.Wrap("wrapper", &notFoundError{ID: 42, Owner: "foo bar", Token: "secret"}, xerrors.OmitFrame())
+
+	scenarios := []struct {
+		name              string
+		serializerFactory func() xerrors.Serializer
+		expectedOut       string
+	}{
+		{
+			name: "colon",
+			serializerFactory: func() xerrors.Serializer {
+				return xerrors.NewTaggedFieldsSerializer(xerrors.NewColonBasicSerializer())
+			},
+			expectedOut: `wrapper: not found [id=42 owner="foo bar" token=secret]`,
+		},
+		{
+			name: "colonRedacting",
+			serializerFactory: func() xerrors.Serializer {
+				return xerrors.NewRedactingSerializer(xerrors.NewTaggedFieldsSerializer(xerrors.NewColonBasicSerializer()))
+			},
+			expectedOut: `wrapper: not found [id=42 owner="foo bar" token=[REDACTED]]`,
+		},
+		{
+			name: "logfmt",
+			serializerFactory: func() xerrors.Serializer {
+				return xerrors.NewLogfmtSerializer(xerrors.WithLogfmtTaggedFields())
+			},
+			expectedOut: `err.0.msg=wrapper err.0.type=*xerrors.wrappingError ` +
+				`err.1.msg="not found" err.1.id=42 err.1.owner="foo bar" err.1.token=secret err.1.type=*xerrors_test.notFoundError`,
+		},
+		{
+			name: "logfmtRedacting",
+			serializerFactory: func() xerrors.Serializer {
+				return xerrors.NewRedactingSerializer(xerrors.NewLogfmtSerializer(xerrors.WithLogfmtTaggedFields()))
+			},
+			expectedOut: `err.0.msg=wrapper err.0.type=*xerrors.wrappingError ` +
+				`err.1.msg="not found" err.1.id=42 err.1.owner="foo bar" err.1.token=[REDACTED] err.1.type=*xerrors_test.notFoundError`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(scenario.serializerFactory)
+
+			buf := bytes.Buffer{}
+			if err := printer.Write(&buf, err); err != nil {
+				t.Fatalf("error serialising error: %s", err)
+			}
+
+			if buf.String() != scenario.expectedOut {
+				t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package xerrors
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// TimedFrameError is a FrameError also recording when, and in which goroutine, the wrapping took place.
+// They are produced by wrapping with RecordTime.
+type TimedFrameError interface {
+	FrameError
+	// FrameTime returns the time of the wrapping, with a monotonic clock reading.
+	FrameTime() time.Time
+	// FrameGoroutine returns the ID of the goroutine of the wrapping, as reported in stack traces.
+	FrameGoroutine() uint64
+}
+
+// IsTimedFrameError is a helper for type casting to TimedFrameError
+func IsTimedFrameError(err error) bool {
+	_, ok := err.(TimedFrameError)
+	return ok
+}
+
+// LastTimedFrameError is a helper for Last with IsTimedFrameError, returning a typed TimedFrameError
+func LastTimedFrameError(err error) TimedFrameError {
+	err = Last(err, IsTimedFrameError)
+	if err == nil {
+		return nil
+	}
+	return err.(TimedFrameError)
+}
+
+// RecordTime has NewWrapping or Wrap methods record the time and goroutine in their frame, see TimedFrameError.
+// The detailed serializers present the time elapsed since the innermost such frame, how long the error took to propagate,
+// and the time of the innermost itself, in UTC.
+// It has no effect if no frame is captured, see OmitFrame and SetFramePolicy.
+// Finding the goroutine is costly, so it is best reserved for errors not produced in hot paths.
+func RecordTime() WrapOptionFunc {
+	return func(opts wrapOptions) wrapOptions {
+		opts.recordTime = true
+		return opts
+	}
+}
+
+type timedFrameError struct {
+	frameError
+	time      time.Time
+	goroutine uint64
+}
+
+func newTimedFrameError(frames [3]uintptr, err error) *timedFrameError {
+	return &timedFrameError{
+		frameError: frameError{frames: frames, Wrapping: Wrapping{err: err}},
+		time:       time.Now(),
+		goroutine:  goroutineID(),
+	}
+}
+
+func (err *timedFrameError) FrameTime() time.Time {
+	return err.time
+}
+
+func (err *timedFrameError) FrameGoroutine() uint64 {
+	return err.goroutine
+}
+
+// NewStaticTimedFrame is NewStaticFrame for a TimedFrameError, of the given time and goroutine.
+func NewStaticTimedFrame(function, file string, line int, at time.Time, goroutine uint64, err error) error {
+	return &staticTimedFrameError{
+		staticFrameError: staticFrameError{function: function, file: file, line: line, Wrapping: Wrapping{err: err}},
+		time:             at,
+		goroutine:        goroutine,
+	}
+}
+
+type staticTimedFrameError struct {
+	staticFrameError
+	time      time.Time
+	goroutine uint64
+}
+
+func (err *staticTimedFrameError) FrameTime() time.Time {
+	return err.time
+}
+
+func (err *staticTimedFrameError) FrameGoroutine() uint64 {
+	return err.goroutine
+}
+
+var (
+	_ TimedFrameError = (*timedFrameError)(nil)
+	_ TimedFrameError = (*staticTimedFrameError)(nil)
+)
+
+var goroutinePrefix = []byte("goroutine ")
+
+// goroutineID parses the ID of the current goroutine from the header of its stack trace, or returns 0 if it can't.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], goroutinePrefix)
+	if i := bytes.IndexByte(b, ' '); i != -1 {
+		b = b[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// frameTimes holds the origin of the frame times of a serialisation, the innermost timed frame of the error.
+// It is found on the first timed frame formatted, so each serialisation walks the error for it once.
+type frameTimes struct {
+	origin TimedFrameError
+}
+
+// format writes the goroutine of the frame, and the time elapsed since the origin.
+// The origin itself has its time written instead, in UTC.
+func (t *frameTimes) format(frameErr TimedFrameError, buf *bytes.Buffer) {
+	if t.origin == nil {
+		// outermost first, the first timed frame wraps all others; innermost first, it is the origin itself
+		for err := range AllFunc(frameErr, IsTimedFrameError) {
+			t.origin = err.(TimedFrameError)
+		}
+	}
+
+	buf.WriteString(" goroutine ")
+	buf.WriteString(strconv.FormatUint(frameErr.FrameGoroutine(), 10))
+
+	if frameErr == t.origin {
+		buf.WriteString(" at ")
+		buf.WriteString(frameErr.FrameTime().UTC().Format(time.RFC3339Nano))
+		return
+	}
+
+	buf.WriteString(" +")
+	buf.WriteString(frameErr.FrameTime().Sub(t.origin.FrameTime()).String())
+}
+
+func (t *frameTimes) reset() {
+	t.origin = nil
+}
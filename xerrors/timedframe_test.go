@@ -0,0 +1,127 @@
+package xerrors_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func TestRecordTime(t *testing.T) {
+	before := time.Now()
+	err := xerrors.Wrap("msg", errors.New("cause"), xerrors.RecordTime())
+	after := time.Now()
+
+	timedErr := xerrors.LastTimedFrameError(err)
+	if timedErr == nil {
+		t.Fatalf("expected a timed frame, got %s", xerrors.DetailString(err))
+	}
+
+	if at := timedErr.FrameTime(); at.Before(before) || at.After(after) {
+		t.Fatalf("expected a time between %s and %s, got %s", before, after, at)
+	}
+
+	if timedErr.FrameGoroutine() == 0 {
+		t.Fatal("expected a goroutine ID")
+	}
+
+	if function, expected := frameFunction(t, err), testPackage+".TestRecordTime"; function != expected {
+		t.Fatalf("mismatched function, expected %q got %q", expected, function)
+	}
+
+	if xerrors.LastTimedFrameError(xerrors.Wrap("msg", errors.New("cause"))) != nil {
+		t.Fatal("expected no timed frame without RecordTime")
+	}
+	if xerrors.LastFrameError(xerrors.Wrap("msg", errors.New("cause"), xerrors.RecordTime(), xerrors.OmitFrame())) != nil {
+		t.Fatal("expected OmitFrame to take precedence")
+	}
+}
+
+func TestRecordTime_goroutines(t *testing.T) {
+	inner := make(chan error)
+	go func() {
+		inner <- xerrors.Wrap("inner", errors.New("cause"), xerrors.RecordTime())
+	}()
+	err := xerrors.Wrap("outer", <-inner, xerrors.RecordTime())
+
+	outerGoroutine := xerrors.LastTimedFrameError(err).FrameGoroutine()
+	innerGoroutine := xerrors.LastTimedFrameError(xerrors.Unwrap(xerrors.Unwrap(err))).FrameGoroutine()
+	if outerGoroutine == innerGoroutine {
+		t.Fatalf("expected different goroutines, got %d for both", outerGoroutine)
+	}
+}
+
+func TestRecordTime_detailString(t *testing.T) {
+	inner := xerrors.Wrap("inner", errors.New("cause"), xerrors.RecordTime())
+	err := xerrors.Wrap("outer", xerrors.Wrap("middle", inner), xerrors.RecordTime())
+
+	expected := regexp.MustCompile(`^outer\(xerrors_test\.TestRecordTime_detailString:timedframe_test\.go:\d+ goroutine \d+ \+[0-9.]+[nµm]?s\): ` +
+		`middle\(xerrors_test\.TestRecordTime_detailString:timedframe_test\.go:\d+\): ` +
+		`inner\(xerrors_test\.TestRecordTime_detailString:timedframe_test\.go:\d+ goroutine \d+ at \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d(\.\d+)?Z\): cause$`)
+	if out := xerrors.DetailString(err); !expected.MatchString(out) {
+		t.Fatalf("mismatched DetailString, expected to match %q got %q", expected, out)
+	}
+}
+
+func TestRecordTime_rewrap(t *testing.T) {
+	err := xerrors.Wrap("outer", xerrors.New("cause"), xerrors.RecordTime())
+	timedErr := xerrors.LastTimedFrameError(err)
+
+	mapped := xerrors.Map(err, func(err error) error {
+		if err.Error() == "cause" {
+			return xerrors.New("replaced")
+		}
+		return err
+	})
+
+	mappedErr := xerrors.LastTimedFrameError(mapped)
+	if mappedErr == nil || !mappedErr.FrameTime().Equal(timedErr.FrameTime()) || mappedErr.FrameGoroutine() != timedErr.FrameGoroutine() {
+		t.Fatalf("expected the timed frame to survive rewrapping, got %s", xerrors.DetailString(mapped))
+	}
+}
+
+func TestRecordTime_static(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 30, 0, 500, time.FixedZone("CET", 3600))
+	err := xerrors.Wrap("outer", xerrors.NewStaticTimedFrame("pkg.Outer", "/src/pkg/outer.go", 20, at.Add(1500*time.Microsecond), 7,
+		xerrors.Wrap("inner", xerrors.NewStaticTimedFrame("pkg.Inner", "/src/pkg/inner.go", 10, at, 3,
+			errors.New("cause")), xerrors.OmitFrame())), xerrors.OmitFrame())
+
+	scenarios := []struct {
+		name     string
+		printer  *xerrors.Printer
+		expected string
+	}{
+		{
+			name:    "detailed",
+			printer: xerrors.NewPrinter(xerrors.NewColonDetailedSerializer),
+			expected: "outer(pkg.Outer:outer.go:20 goroutine 7 +1.5ms): " +
+				"inner(pkg.Inner:inner.go:10 goroutine 3 at 2024-03-01T09:30:00.0000005Z): cause",
+		},
+		{
+			name:    "reverse",
+			printer: xerrors.NewPrinter(xerrors.NewColonDetailedReverseSerializer),
+			expected: "cause <- inner(pkg.Inner:inner.go:10 goroutine 3 at 2024-03-01T09:30:00.0000005Z) " +
+				"<- outer(pkg.Outer:outer.go:20 goroutine 7 +1.5ms)",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			// written twice, the origin of the first must not carry over to the second
+			for range 2 {
+				buf := strings.Builder{}
+				if wErr := scenario.printer.Write(&buf, err); wErr != nil {
+					t.Fatalf("error serialising error: %s", wErr)
+				}
+				if buf.String() != scenario.expected {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expected, buf.String())
+				}
+			}
+		})
+	}
+}
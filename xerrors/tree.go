@@ -0,0 +1,170 @@
+package xerrors
+
+import (
+	"bytes"
+	"io"
+)
+
+var (
+	treeNewline    = []byte("\n")
+	treeOpenIndent = []byte("│  ")
+	treeIndent     = []byte("   ")
+	treeBranch     = []byte("├─ ")
+	treeLastBranch = []byte("└─ ")
+)
+
+type treeEntry struct {
+	depth int
+	last  bool
+}
+
+type treeSerializer struct {
+	firstEntry bool
+
+	// entries holds the layout of every error to be serialised, in order, as given by the Printer.
+	entries []treeEntry
+	next    int
+
+	// open holds, for each depth, if the last entry at that depth has siblings yet to be serialised.
+	open []bool
+
+	// auxiliary slices, kept to minimise memory allocations
+	stack []int
+	seen  []bool
+
+	trimmer FrameTrimmer
+	times   frameTimes
+}
+
+func (s *treeSerializer) Keep(err error) bool {
+	return !IsFrameError(err)
+}
+
+func (s *treeSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	if _, ok := err.(*truncatedError); ok {
+		return false
+	}
+
+	buf.WriteString(typeName(err))
+	buf.WriteString(": ")
+	buf.WriteString(err.Error())
+
+	if frameErr, ok := Reveal(Unwrap(err)).(FrameError); ok {
+		buf.WriteString(" (")
+		formatShortFrame(frameErr, s.trimmer, &s.times, buf)
+		buf.WriteString(")")
+	}
+
+	return true
+}
+
+func (s *treeSerializer) usesLayout() bool {
+	return true
+}
+
+// useLayout computes the depth in the tree of each error to be serialised, and if it is the last of its siblings.
+// The depth counts kept errors only, so an error wrapping a FrameError is the parent of the error the frame wraps.
+// Truncation markers are laid out as any other error, under the error whose wrapped errors they stand for.
+func (s *treeSerializer) useLayout(depths []int) {
+	for _, depth := range depths {
+		for len(s.stack) != 0 && s.stack[len(s.stack)-1] >= depth {
+			s.stack = s.stack[:len(s.stack)-1]
+		}
+		s.entries = append(s.entries, treeEntry{depth: len(s.stack)})
+		s.stack = append(s.stack, depth)
+	}
+
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		depth := s.entries[i].depth
+		for len(s.seen) <= depth {
+			s.seen = append(s.seen, false)
+		}
+
+		s.entries[i].last = !s.seen[depth]
+		s.seen[depth] = true
+		for j := depth + 1; j < len(s.seen); j++ {
+			s.seen[j] = false
+		}
+	}
+}
+
+func (s *treeSerializer) Append(w io.Writer, msg []byte) error {
+	var entry treeEntry
+	if s.next < len(s.entries) {
+		entry = s.entries[s.next]
+		s.next++
+	}
+
+	if s.firstEntry {
+		s.firstEntry = false
+	} else if _, err := w.Write(treeNewline); err != nil {
+		return err
+	}
+
+	for depth := 1; depth < entry.depth; depth++ {
+		indent := treeIndent
+		if s.open[depth] {
+			indent = treeOpenIndent
+		}
+		if _, err := w.Write(indent); err != nil {
+			return err
+		}
+	}
+
+	if entry.depth != 0 {
+		branch := treeBranch
+		if entry.last {
+			branch = treeLastBranch
+		}
+		if _, err := w.Write(branch); err != nil {
+			return err
+		}
+	}
+
+	for len(s.open) <= entry.depth {
+		s.open = append(s.open, false)
+	}
+	s.open[entry.depth] = !entry.last
+
+	_, err := w.Write(msg)
+	return err
+}
+
+// order has Printers always present errors outermost first, the tree being laid out from the outermost error.
+func (s *treeSerializer) order(bool) bool {
+	return false
+}
+
+func (s *treeSerializer) UseFrameTrimmer(t FrameTrimmer) {
+	s.trimmer = t
+}
+
+func (s *treeSerializer) Reset() {
+	s.firstEntry = true
+	s.entries = s.entries[:0]
+	s.next = 0
+	s.open = s.open[:0]
+	s.stack = s.stack[:0]
+	s.seen = s.seen[:0]
+	s.times.reset()
+}
+
+// NewTreeSerializer provides a human-oriented serializer, printing one error per line as a tree.
+// Each line holds the error's type and message, followed by its frame (if it wraps a FrameError) in shortened mode.
+// Wrapped errors are printed as children of the wrapping error, with branches drawn for errors wrapping multiple errors.
+// It is meant for command line tools and debugging, not for logs.
+// The tree is laid out by the Printer, so it holds only the errors kept by any Serializers decorating this one.
+// Truncation markers are drawn where the errors they stand for would have been.
+// It can't be reversed, Printers created WithReverseOrder and Reverse present it errors outermost first regardless.
+func NewTreeSerializer() Serializer {
+	return &treeSerializer{
+		firstEntry: true,
+	}
+}
+
+var defaultTreePrinter = NewPrinter(NewTreeSerializer)
+
+// TreeString serialises an error using the tree implementation of NewTreeSerializer.
+func TreeString(err error) string {
+	return encodeString(err, defaultTreePrinter)
+}
@@ -0,0 +1,76 @@
+package xerrors_test
+
+import (
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+// beware, the line of the Wrap call in this method is required to test frame logic
+func treeFramedError() error {
+	return xerrors.Wrap("outer", xerrors.New("cause"))
+}
+
+func TestTreeString(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut string
+	}{
+		{
+			name:        "nil",
+			err:         nil,
+			expectedOut: "",
+		},
+		{
+			name:        "nonWrapped",
+			err:         xerrors.New("msg"),
+			expectedOut: "xerrors.baseError: msg",
+		},
+		{
+			name: "chain",
+			err:  treeFramedError(),
+			expectedOut: "*xerrors.wrappingError: outer (xerrors_test.treeFramedError:tree_test.go:11)\n" +
+				"└─ xerrors.baseError: cause",
+		},
+		{
+			name: "tree",
+			err: xerrors.Wrap(
+				"outer",
+				multiError{[]error{
+					xerrors.Wrap("left", multiError{[]error{xerrors.New("left_1"), xerrors.New("left_2")}}, xerrors.OmitFrame()),
+					xerrors.Wrap("middle", xerrors.New("middle_cause"), xerrors.OmitFrame()),
+					xerrors.New("right"),
+				}},
+				xerrors.OmitFrame(),
+			),
+			expectedOut: "*xerrors.wrappingError: outer\n" +
+				"└─ xerrors_test.multiError: multi\n" +
+				"   ├─ *xerrors.wrappingError: left\n" +
+				"   │  └─ xerrors_test.multiError: multi\n" +
+				"   │     ├─ xerrors.baseError: left_1\n" +
+				"   │     └─ xerrors.baseError: left_2\n" +
+				"   ├─ *xerrors.wrappingError: middle\n" +
+				"   │  └─ xerrors.baseError: middle_cause\n" +
+				"   └─ xerrors.baseError: right",
+		},
+		{
+			name:        "cycle",
+			err:         xerrors.Wrap("outer", selfError{}, xerrors.OmitFrame()),
+			expectedOut: "*xerrors.wrappingError: outer\n└─ xerrors_test.selfError: self\n   └─ ...(cycle)",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			// twice, to validate Reset
+			for i := 0; i < 2; i++ {
+				if out := xerrors.TreeString(scenario.err); out != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected:\n%s\ngot:\n%s", scenario.expectedOut, out)
+				}
+			}
+		})
+	}
+}
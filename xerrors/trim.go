@@ -0,0 +1,169 @@
+package xerrors
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// FrameTrimmer rewrites the function and file of frames, as reported by FrameLocation, for serialisation.
+type FrameTrimmer func(function, file string) (string, string)
+
+// FrameTrimmerUser is implemented by Serializers that present the location of frames, each with its own default trimming.
+// Printers created WithFrameTrimmer provide their trimmer to the Serializers through it.
+// Serializers decorating other Serializers should forward it to them, as Decorating does.
+type FrameTrimmerUser interface {
+	UseFrameTrimmer(FrameTrimmer)
+}
+
+// useFrameTrimmer forwards the trimmer to the Serializer, if it is a FrameTrimmerUser.
+func useFrameTrimmer(s Serializer, t FrameTrimmer) {
+	if tu, ok := s.(FrameTrimmerUser); ok {
+		tu.UseFrameTrimmer(t)
+	}
+}
+
+// trimFrame applies the trimmer, or TrimBase if it is nil.
+func trimFrame(t FrameTrimmer, function, file string) (string, string) {
+	if t == nil {
+		return trimBase(function, file)
+	}
+	return t(function, file)
+}
+
+func trimBase(function, file string) (string, string) {
+	if i := strings.LastIndexByte(function, '/'); i != -1 {
+		function = function[i+1:]
+	}
+	if i := strings.LastIndexByte(file, '/'); i != -1 {
+		file = file[i+1:]
+	}
+	return function, file
+}
+
+// TrimBase strips everything before the last '/' in both function and file, as in 'pkg.Function:file.go'.
+// It is the default, short but possibly ambiguous across packages with files of the same name.
+func TrimBase() FrameTrimmer {
+	return trimBase
+}
+
+// TrimNone leaves the package-qualified function and the file, as recorded at build time, untouched.
+func TrimNone() FrameTrimmer {
+	return func(function, file string) (string, string) {
+		return function, file
+	}
+}
+
+// TrimModule is TrimModulePath for the main module of the running binary, as per its build information.
+// Binaries built without module support, or without build information, have no main module.
+func TrimModule() FrameTrimmer {
+	var module string
+	if info, ok := debug.ReadBuildInfo(); ok {
+		module = info.Main.Path
+	}
+	return TrimModulePath(module)
+}
+
+// TrimModulePath presents frames of packages in the module relative to the module, as in 'pkg.Function:pkg/file.go'.
+// Frames of other packages are presented by import path, as in 'net/http.Function:net/http/file.go'.
+// Files recorded with -trimpath, which are already relative, are presented in the same manner.
+func TrimModulePath(module string) FrameTrimmer {
+	modulePrefix := module + "/"
+
+	return func(function, file string) (string, string) {
+		pkg := packageOf(function)
+
+		if file != "" && !filepath.IsAbs(file) {
+			// -trimpath files are relative to the module, prefixed by its path and possibly version
+			if i := strings.IndexByte(file, '@'); i != -1 {
+				if j := strings.IndexByte(file[i:], '/'); j != -1 {
+					file = file[:i] + file[i+j:]
+				}
+			}
+		} else if file != "" && pkg != "" && pkg != "main" {
+			file = pkg + "/" + filepath.Base(file)
+		} else {
+			_, file = trimBase("", file)
+		}
+
+		if module == "" {
+			return function, file
+		}
+
+		switch {
+		case pkg == module:
+			function = function[strings.LastIndexByte(module, '/')+1:]
+			file = strings.TrimPrefix(file, modulePrefix)
+		case strings.HasPrefix(function, modulePrefix):
+			function = function[len(modulePrefix):]
+			file = strings.TrimPrefix(file, modulePrefix)
+		}
+
+		return function, file
+	}
+}
+
+// TrimGoPaths strips the GOROOT and GOPATH source and module cache directories from files,
+// as in 'github.com/owner/repo/pkg/file.go', leaving package-qualified function names.
+// Files recorded with -trimpath are not absolute, and are left untouched.
+func TrimGoPaths() FrameTrimmer {
+	var prefixes []string
+	if goroot := os.Getenv("GOROOT"); goroot != "" {
+		prefixes = append(prefixes, filepath.ToSlash(filepath.Join(goroot, "src"))+"/")
+	}
+	if src := goRootSrc(); src != "" {
+		prefixes = append(prefixes, src)
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			gopath = filepath.Join(home, "go")
+		}
+	}
+	for _, dir := range filepath.SplitList(gopath) {
+		prefixes = append(prefixes,
+			filepath.ToSlash(filepath.Join(dir, "pkg", "mod"))+"/",
+			filepath.ToSlash(filepath.Join(dir, "src"))+"/",
+		)
+	}
+
+	return trimPrefixes(prefixes)
+}
+
+// goRootSrc returns the GOROOT source directory the standard library was built from, followed by '/'.
+// It is derived from the file of a function of the runtime package, as recorded at build time and so reported by frames.
+// It is empty if the binary was built with -trimpath, its files then being relative.
+func goRootSrc() string {
+	pc := reflect.ValueOf(runtime.GC).Pointer()
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+
+	file, _ := fn.FileLine(pc)
+	if !filepath.IsAbs(file) {
+		return ""
+	}
+
+	file = filepath.ToSlash(file)
+	i := strings.LastIndex(file, "/runtime/")
+	if i == -1 {
+		return ""
+	}
+	return file[:i+1]
+}
+
+func trimPrefixes(prefixes []string) FrameTrimmer {
+	return func(function, file string) (string, string) {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(file, prefix) {
+				return function, file[len(prefix):]
+			}
+		}
+		return function, file
+	}
+}
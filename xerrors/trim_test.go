@@ -0,0 +1,182 @@
+package xerrors_test
+
+import (
+	"errors"
+	"go/build"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+func TestWithFrameTrimmer(t *testing.T) {
+	const module = "example.com/mod"
+
+	scenarios := []struct {
+		name        string
+		trimmer     xerrors.FrameTrimmer
+		function    string
+		file        string
+		expectedOut string
+	}{
+		{
+			name:        "default",
+			trimmer:     nil,
+			function:    "example.com/mod/pkg.Function",
+			file:        "/src/mod/pkg/file.go",
+			expectedOut: "outer(pkg.Function:file.go:12): cause",
+		},
+		{
+			name:        "base",
+			trimmer:     xerrors.TrimBase(),
+			function:    "example.com/mod/pkg.Function",
+			file:        "/src/mod/pkg/file.go",
+			expectedOut: "outer(pkg.Function:file.go:12): cause",
+		},
+		{
+			name:        "none",
+			trimmer:     xerrors.TrimNone(),
+			function:    "example.com/mod/pkg.Function",
+			file:        "/src/mod/pkg/file.go",
+			expectedOut: "outer(example.com/mod/pkg.Function:/src/mod/pkg/file.go:12): cause",
+		},
+		{
+			name:        "module",
+			trimmer:     xerrors.TrimModulePath(module),
+			function:    "example.com/mod/pkg.Function",
+			file:        "/src/mod/pkg/file.go",
+			expectedOut: "outer(pkg.Function:pkg/file.go:12): cause",
+		},
+		{
+			name:        "moduleMethod",
+			trimmer:     xerrors.TrimModulePath(module),
+			function:    "example.com/mod/pkg.(*T).Method",
+			file:        "/src/mod/pkg/file.go",
+			expectedOut: "outer(pkg.(*T).Method:pkg/file.go:12): cause",
+		},
+		{
+			name:        "moduleRoot",
+			trimmer:     xerrors.TrimModulePath(module),
+			function:    "example.com/mod.Function",
+			file:        "/src/mod/file.go",
+			expectedOut: "outer(mod.Function:file.go:12): cause",
+		},
+		{
+			name:        "moduleMain",
+			trimmer:     xerrors.TrimModulePath(module),
+			function:    "main.main",
+			file:        "/src/mod/cmd/main.go",
+			expectedOut: "outer(main.main:main.go:12): cause",
+		},
+		{
+			name:        "moduleOtherPackage",
+			trimmer:     xerrors.TrimModulePath(module),
+			function:    "net/http.Serve",
+			file:        "/goroot/src/net/http/server.go",
+			expectedOut: "outer(net/http.Serve:net/http/server.go:12): cause",
+		},
+		{
+			name:        "moduleTrimpath",
+			trimmer:     xerrors.TrimModulePath(module),
+			function:    "example.com/mod/pkg.Function",
+			file:        "example.com/mod/pkg/file.go",
+			expectedOut: "outer(pkg.Function:pkg/file.go:12): cause",
+		},
+		{
+			name:        "moduleTrimpathDependency",
+			trimmer:     xerrors.TrimModulePath(module),
+			function:    "example.com/dep/pkg.Function",
+			file:        "example.com/dep@v1.2.0/pkg/file.go",
+			expectedOut: "outer(example.com/dep/pkg.Function:example.com/dep/pkg/file.go:12): cause",
+		},
+		{
+			name:        "noModule",
+			trimmer:     xerrors.TrimModulePath(""),
+			function:    "example.com/mod/pkg.Function",
+			file:        "/src/mod/pkg/file.go",
+			expectedOut: "outer(example.com/mod/pkg.Function:example.com/mod/pkg/file.go:12): cause",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			var opts []xerrors.PrinterOptionFunc
+			if scenario.trimmer != nil {
+				opts = append(opts, xerrors.WithFrameTrimmer(scenario.trimmer))
+			}
+			printer := xerrors.NewPrinter(xerrors.NewColonDetailedSerializer, opts...)
+
+			err := xerrors.Wrap("outer", xerrors.NewStaticFrame(scenario.function, scenario.file, 12, errors.New("cause")), xerrors.OmitFrame())
+
+			buf := strings.Builder{}
+			if wErr := printer.Write(&buf, err); wErr != nil {
+				t.Fatalf("error serialising error: %s", wErr)
+			}
+			if buf.String() != scenario.expectedOut {
+				t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+			}
+		})
+	}
+}
+
+func TestWithFrameTrimmer_decorated(t *testing.T) {
+	printer := xerrors.NewPrinter(
+		func() xerrors.Serializer { return xerrors.WithPrefix(xerrors.NewColonDetailedSerializer(), "error: ") },
+		xerrors.WithFrameTrimmer(xerrors.TrimNone()),
+	)
+
+	err := xerrors.Wrap("outer", xerrors.NewStaticFrame("pkg.Function", "/src/pkg/file.go", 12, errors.New("cause")), xerrors.OmitFrame())
+
+	buf := strings.Builder{}
+	if wErr := printer.Write(&buf, err); wErr != nil {
+		t.Fatalf("error serialising error: %s", wErr)
+	}
+	if expected := "error: outer(pkg.Function:/src/pkg/file.go:12): cause"; buf.String() != expected {
+		t.Fatalf("mismatched output, expected %q got %q", expected, buf.String())
+	}
+}
+
+func TestTrimGoPaths(t *testing.T) {
+	err := xerrors.Wrap("outer", errors.New("cause"))
+	_, file, _ := xerrors.LastFrameError(err).FrameLocation()
+
+	var srcDir string
+	for _, dir := range filepath.SplitList(build.Default.GOPATH) {
+		if dir := filepath.ToSlash(filepath.Join(dir, "src")) + "/"; strings.HasPrefix(file, dir) {
+			srcDir = dir
+		}
+	}
+	if srcDir == "" {
+		t.Skipf("source file %q not within GOPATH", file)
+	}
+
+	printer := xerrors.NewPrinter(xerrors.NewColonDetailedSerializer, xerrors.WithFrameTrimmer(xerrors.TrimGoPaths()))
+	buf := strings.Builder{}
+	if wErr := printer.Write(&buf, err); wErr != nil {
+		t.Fatalf("error serialising error: %s", wErr)
+	}
+
+	if expected := ":" + strings.TrimPrefix(file, srcDir) + ":"; !strings.Contains(buf.String(), expected) {
+		t.Fatalf("expected output containing %q, got %q", expected, buf.String())
+	}
+	if expected := ":" + strings.TrimPrefix(file, srcDir) + ":"; !strings.Contains(xerrors.LastFrameError(err).Error(), expected) {
+		t.Fatalf("expected frame Error containing %q, got %q", expected, xerrors.LastFrameError(err).Error())
+	}
+}
+
+func TestTrimGoPaths_goroot(t *testing.T) {
+	pc := reflect.ValueOf(strings.Cut).Pointer()
+	file, _ := runtime.FuncForPC(pc).FileLine(pc)
+	if !filepath.IsAbs(file) {
+		t.Skipf("source file %q not absolute, built with -trimpath", file)
+	}
+
+	if _, trimmed := xerrors.TrimGoPaths()("strings.Cut", filepath.ToSlash(file)); trimmed != "strings/strings.go" {
+		t.Fatalf("mismatched file, expected %q got %q", "strings/strings.go", trimmed)
+	}
+}
@@ -0,0 +1,231 @@
+package xerrors
+
+import (
+	"bytes"
+	"io"
+)
+
+var (
+	userMessageOpen  = []byte(" [user: ")
+	userMessageClose = []byte("]")
+)
+
+// UserMessage is an error carrying a message that is safe to be shown to end users.
+// Error should still return the internal message, which may be identical.
+type UserMessage interface {
+	Wrapper
+	UserMessage() string
+}
+
+// IsUserMessage is a helper for type casting to UserMessage
+func IsUserMessage(err error) bool {
+	_, ok := err.(UserMessage)
+	return ok
+}
+
+// LastUserMessage is a helper for Last with IsUserMessage, returning a typed UserMessage
+func LastUserMessage(err error) UserMessage {
+	err = Last(err, IsUserMessage)
+	if err == nil {
+		return nil
+	}
+	return err.(UserMessage)
+}
+
+// WithUserMessage wraps an error with a message that is safe to be shown to end users.
+// The message is also the Error of the wrapping error.
+// Wrapping and frame behaviour is identical to that of Wrap.
+func WithUserMessage(msg string, err error, opts ...WrapOptionFunc) error {
+	return &userMessageError{
+		msg:      msg,
+		Wrapping: newWrapping(err, wrapOptions{skip: 1}, opts...),
+	}
+}
+
+type userMessageError struct {
+	msg string
+	Wrapping
+}
+
+func (err *userMessageError) Error() string {
+	return err.msg
+}
+
+func (err *userMessageError) UserMessage() string {
+	return err.msg
+}
+
+var _ UserMessage = (*userMessageError)(nil)
+
+// UserMessagePolicy defines which UserMessage errors in a chain are rendered to users.
+type UserMessagePolicy uint8
+
+const (
+	// UserMessageOutermost renders only the first UserMessage in the chain.
+	// For trees, of errors wrapping multiple errors, it is the first walked, depth-first.
+	UserMessageOutermost UserMessagePolicy = iota
+	// UserMessageInnermost renders only the last UserMessage in the chain, or the last walked in trees.
+	UserMessageInnermost
+	// UserMessageAll renders all UserMessage in the chain, separated by ': '.
+	UserMessageAll
+)
+
+type userSerializer struct {
+	policy     UserMessagePolicy
+	firstEntry bool
+	// truncation is set while appending the Printer's truncation marker, which is not for users.
+	truncation bool
+
+	// reverse is set if Printers present the errors innermost first, see order.
+	reverse  bool
+	maxDepth int
+
+	// root is the error being serialised, as given by the Printer.
+	root error
+
+	// Outermost first, selected is the index in the walk of the UserMessage kept as per the policy, -1 if none.
+	// It is laid out on the first Keep, walked is the number of errors in the walk.
+	// next is the number of errors already kept or not, in the order they are presented.
+	laidOut  bool
+	selected int
+	walked   int
+	next     int
+}
+
+func (s *userSerializer) Keep(err error) bool {
+	if s.policy == UserMessageAll {
+		return IsUserMessage(err)
+	}
+
+	if !s.laidOut {
+		// without a Printer, the first error kept is the outermost
+		root := s.root
+		if root == nil {
+			root = err
+		}
+		s.layout(root)
+	}
+
+	i := s.next
+	if s.reverse {
+		i = s.walked - 1 - s.next
+	}
+	s.next++
+
+	return i == s.selected
+}
+
+// layout walks err once, as Printers do, selecting the first or last UserMessage as per the policy.
+func (s *userSerializer) layout(err error) {
+	s.laidOut = true
+	s.selected = -1
+
+	w := newWalker(true)
+	if s.maxDepth > 0 {
+		w = newWalkerDepth(true, s.maxDepth)
+	}
+
+	w.walk(err, 0, func(_ int, err error) bool {
+		if IsUserMessage(err) && (s.selected == -1 || s.policy == UserMessageInnermost) {
+			s.selected = s.walked
+		}
+		s.walked++
+		return true
+	})
+}
+
+func (s *userSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	// only UserMessages are kept, any other error is the Printer's truncation marker
+	userErr, ok := err.(UserMessage)
+	if !ok {
+		s.truncation = true
+		return true
+	}
+
+	buf.WriteString(userErr.UserMessage())
+	return true
+}
+
+func (s *userSerializer) Append(w io.Writer, msg []byte) error {
+	if s.truncation {
+		s.truncation = false
+		return nil
+	}
+
+	if s.firstEntry {
+		s.firstEntry = false
+	} else if _, err := w.Write(colonSeparator); err != nil {
+		return err
+	}
+
+	_, err := w.Write(msg)
+	return err
+}
+
+func (s *userSerializer) order(reverse bool) bool {
+	s.reverse = reverse
+	return reverse
+}
+
+func (s *userSerializer) useMaxDepth(depth int) {
+	s.maxDepth = depth
+}
+
+func (s *userSerializer) useRoot(err error) {
+	s.root = err
+}
+
+func (s *userSerializer) Reset() {
+	s.firstEntry = true
+	s.truncation = false
+	s.root = nil
+	s.laidOut = false
+	s.walked = 0
+	s.next = 0
+}
+
+// NewUserSerializer provides a serializer that only prints the user messages of UserMessage errors.
+// The policy determines which of these are printed. Truncation markers are not printed, they are not for users.
+func NewUserSerializer(policy UserMessagePolicy) Serializer {
+	return &userSerializer{
+		policy:     policy,
+		firstEntry: true,
+	}
+}
+
+type userAnnotatingSerializer struct {
+	Decorating
+}
+
+func (s *userAnnotatingSerializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	if !s.Serializer.CustomFormat(err, buf) {
+		buf.WriteString(err.Error())
+	}
+
+	if userErr, ok := err.(UserMessage); ok {
+		if msg := userErr.UserMessage(); msg != err.Error() {
+			buf.Write(userMessageOpen)
+			buf.WriteString(msg)
+			buf.Write(userMessageClose)
+		}
+	}
+
+	return true
+}
+
+// NewUserAnnotatingSerializer decorates a Serializer so UserMessage errors print both their internal and user messages.
+// The user message is appended as ' [user: ...]', unless identical to the internal one.
+// It is intended for internal logs, where both messages are of interest.
+func NewUserAnnotatingSerializer(s Serializer) Serializer {
+	return &userAnnotatingSerializer{
+		Decorating: Decorating{Serializer: s},
+	}
+}
+
+var defaultUserPrinter = NewPrinter(func() Serializer { return NewUserSerializer(UserMessageOutermost) })
+
+// UserString serialises only the outermost user message in the error chain, as per UserMessageOutermost.
+// It returns an empty string if there is no UserMessage in the chain, the caller must then choose a generic message.
+func UserString(err error) string {
+	return encodeString(err, defaultUserPrinter)
+}
@@ -0,0 +1,194 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+type internalUserError struct {
+	xerrors.Wrapping
+}
+
+func (internalUserError) Error() string { return "db connection refused" }
+
+func (internalUserError) UserMessage() string { return "service unavailable" }
+
+var _ xerrors.UserMessage = internalUserError{}
+
+func userScenarioError() error {
+	return xerrors.WithUserMessage(
+		"could not load profile",
+		xerrors.Wrap(
+			"reading user 42",
+			internalUserError{xerrors.NewWrapping(xerrors.New("dial tcp"), xerrors.OmitFrame())},
+			xerrors.OmitFrame(),
+		),
+		xerrors.OmitFrame(),
+	)
+}
+
+func TestUserString(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut string
+	}{
+		{
+			name:        "nil",
+			err:         nil,
+			expectedOut: "",
+		},
+		{
+			name:        "noUserMessage",
+			err:         xerrors.Wrap("wrapper", xerrors.New("msg")),
+			expectedOut: "",
+		},
+		{
+			name:        "outermost",
+			err:         userScenarioError(),
+			expectedOut: "could not load profile",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if out := xerrors.UserString(scenario.err); out != scenario.expectedOut {
+				t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, out)
+			}
+		})
+	}
+}
+
+func TestUserSerializers(t *testing.T) {
+	scenarios := []struct {
+		name              string
+		serializerFactory func() xerrors.Serializer
+		expectedOut       string
+	}{
+		{
+			name:              "outermost",
+			serializerFactory: func() xerrors.Serializer { return xerrors.NewUserSerializer(xerrors.UserMessageOutermost) },
+			expectedOut:       "could not load profile",
+		},
+		{
+			name:              "innermost",
+			serializerFactory: func() xerrors.Serializer { return xerrors.NewUserSerializer(xerrors.UserMessageInnermost) },
+			expectedOut:       "service unavailable",
+		},
+		{
+			name:              "all",
+			serializerFactory: func() xerrors.Serializer { return xerrors.NewUserSerializer(xerrors.UserMessageAll) },
+			expectedOut:       "could not load profile: service unavailable",
+		},
+		{
+			name: "annotating",
+			serializerFactory: func() xerrors.Serializer {
+				return xerrors.NewUserAnnotatingSerializer(xerrors.NewColonBasicSerializer())
+			},
+			expectedOut: "could not load profile: reading user 42: db connection refused [user: service unavailable]: dial tcp",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(scenario.serializerFactory)
+
+			// twice, to validate Reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if err := printer.Write(&buf, userScenarioError()); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestUserSerializer_walk(t *testing.T) {
+	tree := multiError{[]error{
+		xerrors.WithUserMessage("left", xerrors.WithUserMessage("left cause", nil, xerrors.OmitFrame()), xerrors.OmitFrame()),
+		xerrors.WithUserMessage("right", nil, xerrors.OmitFrame()),
+	}}
+
+	scenarios := []struct {
+		name        string
+		policy      xerrors.UserMessagePolicy
+		opts        []xerrors.PrinterOptionFunc
+		err         error
+		expectedOut string
+	}{
+		{
+			name:        "outermostReverse",
+			policy:      xerrors.UserMessageOutermost,
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithReverseOrder()},
+			err:         userScenarioError(),
+			expectedOut: "could not load profile",
+		},
+		{
+			name:        "innermostReverse",
+			policy:      xerrors.UserMessageInnermost,
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithReverseOrder()},
+			err:         userScenarioError(),
+			expectedOut: "service unavailable",
+		},
+		{
+			name:        "outermostTree",
+			policy:      xerrors.UserMessageOutermost,
+			err:         tree,
+			expectedOut: "left",
+		},
+		{
+			name:        "innermostTree",
+			policy:      xerrors.UserMessageInnermost,
+			err:         tree,
+			expectedOut: "right",
+		},
+		{
+			name:        "innermostTreeReverse",
+			policy:      xerrors.UserMessageInnermost,
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithReverseOrder()},
+			err:         tree,
+			expectedOut: "right",
+		},
+		{
+			name:        "innermostMaxDepth",
+			policy:      xerrors.UserMessageInnermost,
+			opts:        []xerrors.PrinterOptionFunc{xerrors.WithMaxDepth(2)},
+			err:         userScenarioError(),
+			expectedOut: "could not load profile",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			printer := xerrors.NewPrinter(
+				func() xerrors.Serializer { return xerrors.NewUserSerializer(scenario.policy) },
+				scenario.opts...,
+			)
+
+			// twice, to validate Reset
+			for i := 0; i < 2; i++ {
+				buf := bytes.Buffer{}
+				if err := printer.Write(&buf, scenario.err); err != nil {
+					t.Fatalf("error serialising error: %s", err)
+				}
+
+				if buf.String() != scenario.expectedOut {
+					t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package xerrors
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// DefaultMaxDepth is the default value of MaxDepth.
+const DefaultMaxDepth = 1000
+
+var maxDepth atomic.Int64
+
+func init() {
+	maxDepth.Store(DefaultMaxDepth)
+}
+
+// MaxDepth is the depth beyond which errors are not walked, by any function in this package.
+// It protects against pathologically deep chains, such as those produced in retry loops.
+// Walks of trees, of errors wrapping multiple errors, also visit at most 8 errors per level of depth.
+func MaxDepth() int {
+	return int(maxDepth.Load())
+}
+
+// MaxErrors is the number of errors beyond which errors are not walked, 8 per level of MaxDepth.
+// It bounds walks of trees as MaxDepth bounds those of chains, and so the errors Printers serialise, unless created
+// WithMaxDepth.
+func MaxErrors() int {
+	return MaxDepth() * walkBudgetPerDepth
+}
+
+// SetMaxDepth sets MaxDepth, or resets it to DefaultMaxDepth if depth is not positive.
+// It is safe to be called concurrently, but is meant to be called once at initialisation.
+// Printers may override it with WithMaxDepth.
+func SetMaxDepth(depth int) {
+	if depth <= 0 {
+		depth = DefaultMaxDepth
+	}
+	maxDepth.Store(int64(depth))
+}
+
+// walkBudgetPerDepth is the number of errors a walk may visit for each level of its maximum depth.
+// Trees of multiple wrapping errors may otherwise hold exponentially many errors within the maximum depth.
+const walkBudgetPerDepth = 8
+
+// multiWrapper is an error wrapping multiple errors, as per the standard library's errors.Join convention.
+// Errors implementing it form trees rather than chains, which are walked depth-first.
+type multiWrapper interface {
+	error
+	Unwrap() []error
+}
+
+// walker is the shared implementation of every walk of an error chain (or tree).
+// It stops at maxDepth, at cycles and once its budget is spent, recording why it did so.
+type walker struct {
+	// reveal defines if errors hidden by Opaque are walked as if they were not.
+	reveal   bool
+	maxDepth int
+	// counting is set for the walks of countOmitted, which stop at maxDepth without counting further.
+	counting bool
+	// budget is the number of errors yet to be walked or counted as omitted.
+	budget int
+	// ancestors holds the multiWrappers on the path being walked, meeting any of them again is a cycle.
+	ancestors []error
+	// mark, if set, is called in place of the errors not walked, with their depth and a Truncation marker for them.
+	mark func(depth int, marker error) bool
+
+	// omitted is the number of errors not walked because they are beyond maxDepth.
+	omitted int
+	// omittedMore is set if omitted does not account for all omitted errors.
+	omittedMore bool
+	// cycle is set if the walk stopped on finding a cycle.
+	cycle bool
+	// spent is set once the budget has run out.
+	spent bool
+}
+
+func newWalker(reveal bool) walker {
+	return newWalkerDepth(reveal, MaxDepth())
+}
+
+func newWalkerDepth(reveal bool, maxDepth int) walker {
+	return walker{
+		reveal:   reveal,
+		maxDepth: maxDepth,
+		budget:   maxDepth * walkBudgetPerDepth,
+	}
+}
+
+// truncated reports if the walk did not cover all errors.
+func (w *walker) truncated() bool {
+	return w.cycle || w.omitted != 0 || w.omittedMore
+}
+
+// walk yields err and all its wrapped errors (recursively), depth-first, along with their depth.
+// It returns false if yield did.
+//
+// Cycles are detected with Brent's algorithm within single chains, comparing errors with identical.
+// Any cycle through a multiWrapper meets it again, so those are detected by comparing them with their ancestors.
+// Cycles of multiWrappers that are not comparable, and trees too large, are stopped by the budget instead.
+func (w *walker) walk(err error, depth int, yield func(int, error) bool) bool {
+	tortoise, power, lambda := err, 1, 0
+
+	for ; err != nil; depth++ {
+		if w.reveal {
+			err = Reveal(err)
+		}
+
+		if depth >= w.maxDepth {
+			if w.counting {
+				w.omittedMore = true
+				return true
+			}
+			omitted, more := w.countOmitted(err)
+			return w.marker(depth, omitted, more, false)
+		}
+
+		if w.budget <= 0 {
+			w.omittedMore = true
+			if w.spent {
+				return true
+			}
+			w.spent = true
+			return w.marker(depth, 0, true, false)
+		}
+
+		mErr, isMulti := err.(multiWrapper)
+		if isMulti {
+			for _, ancestor := range w.ancestors {
+				if identical(ancestor, err) {
+					w.cycle = true
+					return w.marker(depth, 0, false, true)
+				}
+			}
+		}
+
+		w.budget--
+		if !yield(depth, err) {
+			return false
+		}
+
+		if isMulti {
+			return w.walkChildren(mErr, depth+1, yield)
+		}
+
+		if err = Unwrap(err); err != nil && identical(err, tortoise) {
+			w.cycle = true
+			return w.marker(depth+1, 0, false, true)
+		}
+
+		if lambda++; lambda == power {
+			tortoise, power, lambda = err, power*2, 0
+		}
+	}
+
+	return true
+}
+
+// walkChildren walks the errors wrapped by a multiWrapper, with it as their ancestor.
+func (w *walker) walkChildren(mErr multiWrapper, depth int, yield func(int, error) bool) bool {
+	w.ancestors = append(w.ancestors, mErr)
+	defer func() { w.ancestors = w.ancestors[:len(w.ancestors)-1] }()
+
+	for _, child := range mErr.Unwrap() {
+		if !w.walk(child, depth, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// marker calls mark, if set, with a Truncation marker for the errors not walked from depth onwards.
+// It returns false if mark did.
+func (w *walker) marker(depth, omitted int, more, cycle bool) bool {
+	if w.mark == nil {
+		return true
+	}
+	return w.mark(depth, &truncatedError{omitted: omitted, omittedMore: more, cycle: cycle})
+}
+
+// countOmitted counts the errors from err onwards, up to another maxDepth of them.
+// The count shares the budget and ancestors of the walk, so it can't multiply its cost.
+// It returns the errors counted, and if there were more than that.
+func (w *walker) countOmitted(err error) (int, bool) {
+	sub := walker{
+		reveal:    w.reveal,
+		maxDepth:  w.maxDepth,
+		counting:  true,
+		budget:    w.budget,
+		ancestors: w.ancestors,
+	}
+
+	var omitted int
+	sub.walk(err, 0, func(int, error) bool {
+		omitted++
+		return true
+	})
+
+	w.omitted += omitted
+	w.budget = sub.budget
+	more := sub.truncated()
+	if more {
+		w.omittedMore = true
+	}
+	return omitted, more
+}
+
+// Truncation is implemented by the marker Printers serialise in place of the errors their walk did not cover,
+// those beyond MaxDepth (or WithMaxDepth) and those past a cycle. It wraps no error.
+// Serializers may use it to tell the marker apart from the errors of the chain.
+type Truncation interface {
+	error
+	// Truncation returns the number of errors omitted, if more were omitted than counted, and if a cycle was met.
+	Truncation() (omitted int, more, cycle bool)
+}
+
+// IsTruncation is a helper for type casting to Truncation
+func IsTruncation(err error) bool {
+	_, ok := err.(Truncation)
+	return ok
+}
+
+// NewTruncation produces a Truncation marker, as serialised by Printers.
+// It is intended for decoders of serialised errors, it is then the innermost error of the chain.
+func NewTruncation(omitted int, more, cycle bool) error {
+	return &truncatedError{omitted: omitted, omittedMore: more, cycle: cycle}
+}
+
+// truncatedError is the marker written by the Printer when a walk is truncated.
+type truncatedError struct {
+	omitted     int
+	omittedMore bool
+	cycle       bool
+}
+
+func (err *truncatedError) Error() string {
+	if err.cycle {
+		return "...(cycle)"
+	}
+
+	more := " more)"
+	if err.omittedMore {
+		more = "+ more)"
+	}
+	return "...(" + strconv.Itoa(err.omitted) + more
+}
+
+func (*truncatedError) Unwrap() error {
+	return nil
+}
+
+func (err *truncatedError) Truncation() (int, bool, bool) {
+	return err.omitted, err.omittedMore, err.cycle
+}
+
+var _ Truncation = (*truncatedError)(nil)
@@ -0,0 +1,309 @@
+package xerrors_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+type selfError struct{}
+
+func (selfError) Error() string { return "self" }
+
+func (err selfError) Unwrap() error { return err }
+
+type loopError struct {
+	msg  string
+	next *loopError
+}
+
+func (err *loopError) Error() string { return err.msg }
+
+func (err *loopError) Unwrap() error { return err.next }
+
+func newLoopError() error {
+	a, b, c := &loopError{msg: "a"}, &loopError{msg: "b"}, &loopError{msg: "c"}
+	a.next, b.next, c.next = b, c, b
+	return a
+}
+
+// selfJoinError is a multiple wrapping error wrapping itself, twice.
+type selfJoinError struct{}
+
+func (selfJoinError) Error() string { return "join" }
+
+func (err selfJoinError) Unwrap() []error { return []error{err, err} }
+
+// loopJoinError is a multiple wrapping error, made to be wrapped by one of the errors it wraps.
+type loopJoinError struct {
+	errs []error
+}
+
+func (*loopJoinError) Error() string { return "join" }
+
+func (err *loopJoinError) Unwrap() []error { return err.errs }
+
+func newLoopJoinError() error {
+	err := &loopJoinError{}
+	err.errs = []error{xerrors.Wrap("left", err, xerrors.OmitFrame()), xerrors.Wrap("right", err, xerrors.OmitFrame())}
+	return err
+}
+
+// joinTree is a tree of multiple wrapping errors, each wrapping the same error twice, with 2^depth leaves.
+func joinTree(depth int) error {
+	err := xerrors.New("leaf")
+	for i := 0; i < depth; i++ {
+		err = multiError{[]error{err, err}}
+	}
+	return err
+}
+
+func deepError(depth int) error {
+	err := xerrors.New("msg")
+	for i := 1; i < depth; i++ {
+		err = xerrors.Wrap("wrapper", err, xerrors.OmitFrame())
+	}
+	return err
+}
+
+func TestCycles(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut string
+	}{
+		{
+			name:        "self",
+			err:         xerrors.Wrap("wrapper", selfError{}, xerrors.OmitFrame()),
+			expectedOut: "wrapper: self: ...(cycle)",
+		},
+		{
+			name:        "loop",
+			err:         newLoopError(),
+			expectedOut: "a: b: c: ...(cycle)",
+		},
+		{
+			name:        "multiSelf",
+			err:         selfJoinError{},
+			expectedOut: "join: ...(cycle)",
+		},
+		{
+			name:        "multiLoop",
+			err:         newLoopJoinError(),
+			expectedOut: "join: left: right: ...(cycle)",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			if out := xerrors.String(scenario.err); out != scenario.expectedOut {
+				t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, out)
+			}
+
+			if xerrors.Cause(scenario.err) != nil {
+				t.Fatal("cyclic errors must not have a cause")
+			}
+
+			if !xerrors.Similar(scenario.err, scenario.err) {
+				t.Fatal("an error must always be similar to itself")
+			}
+
+			if count := xerrors.Count(scenario.err); count > 10 {
+				t.Fatalf("expecting walk to stop shortly after the cycle, walked %d errors", count)
+			}
+		})
+	}
+}
+
+// dataError is comparable by type, but comparing two of them panics as they hold slices.
+type dataError struct {
+	data any
+	msg  string
+	err  error
+}
+
+func (err dataError) Error() string { return err.msg }
+
+func (err dataError) Unwrap() error { return err.err }
+
+func TestCycles_uncomparable(t *testing.T) {
+	err := dataError{msg: "outer", data: []int{1}, err: dataError{msg: "inner", data: []int{2}, err: xerrors.New("cause")}}
+
+	if expected, out := "outer: inner: cause", xerrors.String(err); out != expected {
+		t.Fatalf("mismatched output, expected %q got %q", expected, out)
+	}
+	if count := xerrors.Count(err); count != 3 {
+		t.Fatalf("expected 3 errors, walked %d", count)
+	}
+	if !xerrors.Similar(err, err) {
+		t.Fatal("an error must always be similar to itself")
+	}
+}
+
+func TestWalkBudget(t *testing.T) {
+	// 2^40 leaves, only walkable thanks to the budget
+	err := joinTree(40)
+
+	if count := xerrors.Count(err); count > 8*xerrors.DefaultMaxDepth {
+		t.Fatalf("expected the walk to be limited to %d errors, walked %d", 8*xerrors.DefaultMaxDepth, count)
+	}
+
+	if out := xerrors.String(err); !strings.HasSuffix(out, "+ more)") {
+		t.Fatalf("expected a truncation marker, got %q", out)
+	}
+
+	// countOmitted shares the budget, beyond the Printer's depth
+	printer := xerrors.NewPrinter(xerrors.NewColonBasicSerializer, xerrors.WithMaxDepth(3))
+	buf := bytes.Buffer{}
+	if wErr := printer.Write(&buf, err); wErr != nil {
+		t.Fatalf("error serialising error: %s", wErr)
+	}
+	if expected := "multi: multi: multi: multi: ...(20+ more)"; buf.String() != expected {
+		t.Fatalf("mismatched output, expected %q got %q", expected, buf.String())
+	}
+}
+
+func TestWithMaxDepth(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut string
+	}{
+		{
+			name:        "shallow",
+			err:         deepError(3),
+			expectedOut: "wrapper: wrapper: msg",
+		},
+		{
+			name:        "deep",
+			err:         deepError(5),
+			expectedOut: "wrapper: wrapper: wrapper: ...(2 more)",
+		},
+		{
+			name:        "veryDeep",
+			err:         deepError(10),
+			expectedOut: "wrapper: wrapper: wrapper: ...(3+ more)",
+		},
+		{
+			name:        "deepCycle",
+			err:         xerrors.Wrap("wrapper", xerrors.Wrap("wrapper", xerrors.Wrap("wrapper", selfError{}, xerrors.OmitFrame()), xerrors.OmitFrame()), xerrors.OmitFrame()),
+			expectedOut: "wrapper: wrapper: wrapper: ...(1+ more)",
+		},
+	}
+
+	printer := xerrors.NewPrinter(xerrors.NewColonBasicSerializer, xerrors.WithMaxDepth(3))
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := printer.Write(&buf, scenario.err); err != nil {
+				t.Fatalf("error serialising error: %s", err)
+			}
+
+			if buf.String() != scenario.expectedOut {
+				t.Fatalf("mismatched output, expected %q got %q", scenario.expectedOut, buf.String())
+			}
+		})
+	}
+}
+
+func TestSetMaxDepth(t *testing.T) {
+	defer xerrors.SetMaxDepth(0)
+
+	xerrors.SetMaxDepth(2)
+
+	if depth := xerrors.MaxDepth(); depth != 2 {
+		t.Fatalf("expected MaxDepth 2, got %d", depth)
+	}
+
+	if count := xerrors.Count(deepError(5)); count != 2 {
+		t.Fatalf("expected walk to be limited to 2 errors, walked %d", count)
+	}
+
+	if xerrors.Cause(deepError(5)) != nil {
+		t.Fatal("expected no cause to be found beyond MaxDepth")
+	}
+
+	xerrors.SetMaxDepth(0)
+
+	if depth := xerrors.MaxDepth(); depth != xerrors.DefaultMaxDepth {
+		t.Fatalf("expected MaxDepth to be reset to %d, got %d", xerrors.DefaultMaxDepth, depth)
+	}
+}
+
+func TestTruncation(t *testing.T) {
+	var marker error
+	printer := xerrors.NewPrinter(
+		func() xerrors.Serializer {
+			return xerrors.OverrideFormat(xerrors.NewColonBasicSerializer(), func(err error, buf *bytes.Buffer) bool {
+				if !xerrors.IsTruncation(err) {
+					return false
+				}
+				marker = err
+				buf.WriteString("[truncated]")
+				return true
+			})
+		},
+		xerrors.WithMaxDepth(3),
+	)
+
+	buf := bytes.Buffer{}
+	if err := printer.Write(&buf, deepError(10)); err != nil {
+		t.Fatalf("error serialising error: %s", err)
+	}
+	if expected := "wrapper: wrapper: wrapper: [truncated]"; buf.String() != expected {
+		t.Fatalf("mismatched output, expected %q got %q", expected, buf.String())
+	}
+
+	omitted, more, cycle := marker.(xerrors.Truncation).Truncation()
+	if omitted != 3 || !more || cycle {
+		t.Fatalf("mismatched truncation, expected 3 true false got %d %t %t", omitted, more, cycle)
+	}
+
+	rebuilt := xerrors.NewTruncation(omitted, more, cycle)
+	if rebuilt.Error() != marker.Error() {
+		t.Fatalf("mismatched message, expected %q got %q", marker.Error(), rebuilt.Error())
+	}
+	if expected := "...(cycle)"; xerrors.NewTruncation(0, false, true).Error() != expected {
+		t.Fatalf("mismatched message, expected %q got %q", expected, xerrors.NewTruncation(0, false, true).Error())
+	}
+}
+
+func TestRevealed(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedOut []string
+	}{
+		{
+			name:        "opaque",
+			err:         xerrors.Wrap("outer", xerrors.Opaque(xerrors.New("hidden")), xerrors.OmitFrame()),
+			expectedOut: []string{"outer", "hidden"},
+		},
+		{
+			name:        "cycle",
+			err:         xerrors.Wrap("wrapper", selfError{}, xerrors.OmitFrame()),
+			expectedOut: []string{"wrapper", "self", "...(cycle)"},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			var out []string
+			for err := range xerrors.Revealed(scenario.err) {
+				out = append(out, err.Error())
+			}
+			if strings.Join(out, ", ") != strings.Join(scenario.expectedOut, ", ") {
+				t.Fatalf("mismatched errors, expected %q got %q", scenario.expectedOut, out)
+			}
+		})
+	}
+}
@@ -23,20 +23,22 @@ func Unwrap(err error) error {
 // It is the main means to identify if a certain error type exists within the wrap chain of another.
 // Typed helpers (that return a certain error type or more restrictive error interface) are encouraged.
 func Last(err error, f func(error) bool) error {
-	for ; err != nil; err = Unwrap(err) {
-		if f(err) {
-			return err
-		}
+	for err := range AllFunc(err, f) {
+		return err
 	}
 
 	return nil
 }
 
 func cause(err error) bool {
+	if mErr, ok := err.(multiWrapper); ok {
+		return len(mErr.Unwrap()) == 0
+	}
 	return Unwrap(err) == nil
 }
 
 // Cause returns the last error in the wrap chain, defined as that which wraps no other error.
+// For errors wrapping multiple errors, it is the first such error found depth-first.
 func Cause(err error) error {
 	return Last(err, cause)
 }
@@ -45,6 +47,8 @@ func Cause(err error) error {
 // Embed it in an error type and it provides the Unwrap method.
 type Wrapping struct {
 	err error
+	// framed is set if err is known to hold a FrameError, see chainFramed.
+	framed bool
 }
 
 // Unwrap returns the internal wrapped error
@@ -52,6 +56,22 @@ func (w Wrapping) Unwrap() error {
 	return w.err
 }
 
+// framedChain reports if the wrapped error is known to hold a FrameError.
+func (w Wrapping) framedChain() bool {
+	return w.framed
+}
+
+// chainFramed reports if err is known to hold a FrameError without walking it:
+// if it is one, or wraps one by way of a Wrapping produced by NewWrapping or the Wrap methods.
+// It may return false for errors holding one, but never true for errors holding none.
+func chainFramed(err error) bool {
+	if IsFrameError(err) {
+		return true
+	}
+	fc, ok := err.(interface{ framedChain() bool })
+	return ok && fc.framedChain()
+}
+
 // NewWrapping initialises a Wrapping.
 // By default it will also produce a FrameError with information about the caller of Wrap.
 // This can be either disabled or the caller modified by use of optional arguments.
@@ -60,14 +80,18 @@ func NewWrapping(err error, opts ...WrapOptionFunc) Wrapping {
 }
 
 type wrapOptions struct {
-	omitFrame bool
-	skip      uint8
+	omitFrame      bool
+	omitDuplicate  bool
+	duplicateLines bool
+	recordTime     bool
+	skip           uint8
 }
 
 // WrapOptionFunc represent optional arguments to NewWrapping or Wrap methods.
 type WrapOptionFunc = func(wrapOptions) wrapOptions
 
 // OmitFrame stops frames from being included in NewWrapping or Wrap methods.
+// Otherwise whether they are included is decided by the FramePolicy, see SetFramePolicy.
 func OmitFrame() WrapOptionFunc {
 	return func(opts wrapOptions) wrapOptions {
 		opts.omitFrame = true
@@ -76,6 +100,8 @@ func OmitFrame() WrapOptionFunc {
 }
 
 // SkipNFrames can be used to have the frame reported in NewWrapping or Wrap be something other than the calling one.
+// It is fragile to refactorings, marking the functions skipped with Helper or SetHelperPackage is often preferable.
+// It is applied first, helpers are skipped from the frame it lands on, whether those it skipped were helpers or not.
 func SkipNFrames(skip uint8) WrapOptionFunc {
 	return func(opts wrapOptions) wrapOptions {
 		opts.skip += skip
@@ -83,14 +109,45 @@ func SkipNFrames(skip uint8) WrapOptionFunc {
 	}
 }
 
+// OmitDuplicateFrame stops frames from being included in NewWrapping or Wrap methods
+// if in the same function as the next frame of the wrapped chain, and if matchLine also at the same line.
+// The frame returned by LastFrameError is then the wrapped one, in the same function.
+func OmitDuplicateFrame(matchLine bool) WrapOptionFunc {
+	return func(opts wrapOptions) wrapOptions {
+		opts.omitDuplicate = true
+		opts.duplicateLines = matchLine
+		return opts
+	}
+}
+
 func newWrapping(err error, wrapOpts wrapOptions, opts ...WrapOptionFunc) Wrapping {
 	for _, opt := range opts {
 		wrapOpts = opt(wrapOpts)
 	}
 
 	if wrapOpts.omitFrame {
-		return Wrapping{err: err}
+		return Wrapping{err: err, framed: chainFramed(err)}
+	}
+
+	skip := wrapOpts.skip + 1
+	skip += helperFrames(skip)
+
+	if !captureFrame(skip, err) {
+		return Wrapping{err: err, framed: chainFramed(err)}
+	}
+
+	frames := caller(skip)
+
+	if wrapOpts.omitDuplicate {
+		function, _, line := location(frames)
+		if sameFrame(function, line, LastFrameError(err), wrapOpts.duplicateLines) {
+			return Wrapping{err: err, framed: true}
+		}
+	}
+
+	if wrapOpts.recordTime {
+		return Wrapping{err: newTimedFrameError(frames, err), framed: true}
 	}
 
-	return Wrapping{err: newFrameError(wrapOpts.skip+1, err)}
+	return Wrapping{err: &frameError{frames: frames, Wrapping: Wrapping{err: err}}, framed: true}
 }
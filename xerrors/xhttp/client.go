@@ -0,0 +1,211 @@
+package xhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+// MaxProblemSize is the maximum size of the problem documents read by ErrorFromResponse, larger ones are ignored.
+const MaxProblemSize = 1 << 16
+
+var errNotObject = errors.New("problem document is not a JSON object")
+
+// UnmarshalJSON reads a problem document, with any members other than the standard ones as extensions.
+// Extensions keep the order of the document, and numbers are read as int64 if integers and float64 otherwise.
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	if t, err := dec.Token(); err != nil {
+		return err
+	} else if t != json.Delim('{') {
+		return errNotObject
+	}
+
+	*p = Problem{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := t.(string)
+
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+
+		// as per RFC 9457, standard members of the wrong type are ignored
+		switch key {
+		case "type":
+			p.Type, _ = value.(string)
+		case "title":
+			p.Title, _ = value.(string)
+		case "status":
+			if n, ok := value.(json.Number); ok {
+				status, _ := strconv.Atoi(n.String())
+				p.Status = status
+			}
+		case "detail":
+			p.Detail, _ = value.(string)
+		case "instance":
+			p.Instance, _ = value.(string)
+		default:
+			p.Extensions = append(p.Extensions, xerrors.Field{Key: key, Value: fromJSONNumbers(value)})
+		}
+	}
+
+	if p.Type == "" {
+		p.Type = DefaultType
+	}
+
+	return nil
+}
+
+// fromJSONNumbers replaces the json.Number in value, recursively, with int64 or float64.
+func fromJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = fromJSONNumbers(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = fromJSONNumbers(v[k])
+		}
+	}
+
+	return value
+}
+
+// RemoteError records that the errors it wraps were received from another service.
+// It is deliberately not a StatusCoder: the status of a remote failure is seldom that of the local one.
+type RemoteError struct {
+	// Service is the name of the service the errors were received from.
+	Service string
+	// Status is the HTTP status of the response.
+	Status int
+	// Problem is the problem document received, or that built from Status if the response held none.
+	Problem *Problem
+
+	xerrors.Wrapping
+}
+
+func (err *RemoteError) Error() string {
+	if err.Service == "" {
+		return "remote status=" + strconv.Itoa(err.Status)
+	}
+	return "remote " + err.Service + " status=" + strconv.Itoa(err.Status)
+}
+
+// IsRemoteError is a helper for type casting to *RemoteError
+func IsRemoteError(err error) bool {
+	_, ok := err.(*RemoteError)
+	return ok
+}
+
+// LastRemoteError is a helper for Last with IsRemoteError, returning a typed *RemoteError
+func LastRemoteError(err error) *RemoteError {
+	err = xerrors.Last(err, IsRemoteError)
+	if err == nil {
+		return nil
+	}
+	return err.(*RemoteError)
+}
+
+// problemError is the error of problem documents with no registered ProblemFactory.
+// Its message is the detail, or else the title, and its fields the extensions.
+type problemError struct {
+	p *Problem
+	xerrors.Wrapping
+}
+
+func (err *problemError) Error() string {
+	if err.p.Detail != "" {
+		return err.p.Detail
+	}
+	return err.p.Title
+}
+
+func (err *problemError) Fields() []xerrors.Field {
+	return err.p.Extensions
+}
+
+var _ xerrors.Fielder = (*problemError)(nil)
+
+// ProblemFactory builds the error of a problem document.
+type ProblemFactory = func(*Problem) error
+
+var problemFactories sync.Map
+
+// RegisterProblemType registers the factory building the errors of problem documents of the given type.
+// Without one, the error's message is the problem's detail, or else its title, and its fields the extensions.
+func RegisterProblemType(typ string, f ProblemFactory) {
+	problemFactories.Store(typ, f)
+}
+
+// ErrorFromProblem reconstructs the error chain of a problem document received from a service.
+// It is a RemoteError wrapping the error built by the ProblemFactory registered for its type, if any.
+func ErrorFromProblem(service string, status int, p *Problem) error {
+	var err error
+	if f, ok := problemFactories.Load(p.Type); ok {
+		err = f.(ProblemFactory)(p)
+	} else {
+		err = &problemError{p: p}
+	}
+
+	return &RemoteError{
+		Service:  service,
+		Status:   status,
+		Problem:  p,
+		Wrapping: xerrors.NewWrapping(err, xerrors.OmitFrame()),
+	}
+}
+
+// ErrorFromResponse reconstructs the error chain of a failed response, one with a status of 400 or higher.
+// It returns nil for other responses, and does not read their body.
+// Responses without a problem document, or an invalid one, are treated as having one with only the status and title.
+// The body of failed responses is read, up to MaxProblemSize, but not closed.
+func ErrorFromResponse(service string, resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	p := &Problem{}
+	if !readProblem(resp, p) {
+		*p = Problem{
+			Type:   DefaultType,
+			Title:  http.StatusText(resp.StatusCode),
+			Status: resp.StatusCode,
+		}
+	}
+
+	return ErrorFromProblem(service, resp.StatusCode, p)
+}
+
+func readProblem(resp *http.Response, p *Problem) bool {
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != ContentType {
+		return false
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, MaxProblemSize+1))
+	if err != nil || len(b) > MaxProblemSize {
+		return false
+	}
+
+	return p.UnmarshalJSON(b) == nil
+}
@@ -0,0 +1,191 @@
+package xhttp_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors/xhttp"
+)
+
+const outOfCreditType = "https://example.com/probs/out-of-credit"
+
+type outOfCreditError struct {
+	balance int64
+	xerrors.Wrapping
+}
+
+func (err *outOfCreditError) Error() string { return "out of credit" }
+
+func init() {
+	xhttp.RegisterProblemType(outOfCreditType, func(p *xhttp.Problem) error {
+		err := &outOfCreditError{}
+		for _, field := range p.Extensions {
+			if field.Key == "balance" {
+				err.balance, _ = field.Value.(int64)
+			}
+		}
+		return err
+	})
+}
+
+func TestErrorFromResponse(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/users/", xhttp.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		err := xhttp.WithStatus(http.StatusNotFound, errors.New("no rows"))
+		return xerrors.WithUserMessage("the user does not exist", xerrors.WithFields(err, xerrors.Field{Key: "user_id", Value: 7}), xerrors.OmitFrame())
+	}, xhttp.WithPublicFields("user_id")))
+	mux.Handle("/orders", xhttp.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		err := xerrors.WithFields(xhttp.WithStatus(http.StatusForbidden, errors.New("balance too low")), xerrors.Field{Key: "balance", Value: 30})
+		return xhttp.WithProblemType(outOfCreditType, "You do not have enough credit.", err)
+	}, xhttp.WithPublicFields("balance")))
+	mux.HandleFunc("/gateway", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	get := func(t *testing.T, path string) error {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		defer resp.Body.Close()
+
+		return xhttp.ErrorFromResponse("users", resp)
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		if err := get(t, "/ok"); err != nil {
+			t.Fatalf("unexpected error: %s", xerrors.String(err))
+		}
+	})
+
+	t.Run("untyped", func(t *testing.T) {
+		err := get(t, "/users/7")
+
+		if expected := "remote users status=404: the user does not exist"; xerrors.String(err) != expected {
+			t.Fatalf("mismatched String, expected %q got %q", expected, xerrors.String(err))
+		}
+
+		rErr := xhttp.LastRemoteError(err)
+		if rErr == nil {
+			t.Fatal("expected a RemoteError")
+		}
+		if rErr.Service != "users" || rErr.Status != http.StatusNotFound || rErr.Problem.Instance != "/users/7" {
+			t.Fatalf("mismatched RemoteError %+v", rErr)
+		}
+
+		expectedFields := []xerrors.Field{{Key: "user_id", Value: int64(7)}}
+		if fields := xerrors.Fields(err); !reflect.DeepEqual(fields, expectedFields) {
+			t.Fatalf("mismatched Fields, expected %v got %v", expectedFields, fields)
+		}
+
+		if !xerrors.Similar(err, get(t, "/users/8")) {
+			t.Fatal("expected errors of the same remote failure to be similar")
+		}
+
+		// RemoteError is not a StatusCoder, the status is not propagated
+		if status := xhttp.StatusOf(err); status != http.StatusInternalServerError {
+			t.Fatalf("mismatched StatusOf, expected %d got %d", http.StatusInternalServerError, status)
+		}
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		err := get(t, "/orders")
+
+		cErr, ok := xerrors.Last(err, func(err error) bool {
+			_, ok := err.(*outOfCreditError)
+			return ok
+		}).(*outOfCreditError)
+		if !ok {
+			t.Fatalf("expected an outOfCreditError, got %s", xerrors.String(err))
+		}
+		if cErr.balance != 30 {
+			t.Fatalf("mismatched balance, expected 30 got %d", cErr.balance)
+		}
+
+		if expected := "remote users status=403: out of credit"; xerrors.String(err) != expected {
+			t.Fatalf("mismatched String, expected %q got %q", expected, xerrors.String(err))
+		}
+	})
+
+	t.Run("notProblem", func(t *testing.T) {
+		err := get(t, "/gateway")
+
+		if expected := "remote users status=502: Bad Gateway"; xerrors.String(err) != expected {
+			t.Fatalf("mismatched String, expected %q got %q", expected, xerrors.String(err))
+		}
+	})
+}
+
+func TestProblem_UnmarshalJSON(t *testing.T) {
+	scenarios := []struct {
+		name            string
+		in              string
+		expectedProblem xhttp.Problem
+		expectedErr     bool
+	}{
+		{
+			name: "full",
+			in:   `{"type":"https://example.com/probs/x","title":"X","status":409,"detail":"d","instance":"/i","b":1.5,"a":[1,"s"],"c":{"n":2}}`,
+			expectedProblem: xhttp.Problem{
+				Type:     "https://example.com/probs/x",
+				Title:    "X",
+				Status:   409,
+				Detail:   "d",
+				Instance: "/i",
+				Extensions: []xerrors.Field{
+					{Key: "b", Value: 1.5},
+					{Key: "a", Value: []any{int64(1), "s"}},
+					{Key: "c", Value: map[string]any{"n": int64(2)}},
+				},
+			},
+		},
+		{
+			name:            "defaultType",
+			in:              `{"title":"X","status":"409"}`,
+			expectedProblem: xhttp.Problem{Type: xhttp.DefaultType, Title: "X"},
+		},
+		{
+			name:        "notObject",
+			in:          `[]`,
+			expectedErr: true,
+		},
+		{
+			name:        "invalid",
+			in:          `{"title":`,
+			expectedErr: true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			var p xhttp.Problem
+			err := p.UnmarshalJSON([]byte(scenario.in))
+			if scenario.expectedErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(p, scenario.expectedProblem) {
+				t.Fatalf("mismatched problem, expected %+v got %+v", scenario.expectedProblem, p)
+			}
+		})
+	}
+}
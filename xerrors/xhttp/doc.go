@@ -0,0 +1,12 @@
+// Package xhttp renders xerrors chains as RFC 9457 problem details documents, for HTTP APIs.
+//
+// NewProblem builds the document of an error.
+// Its status is that of the outermost StatusCoder error in the chain, or of the StatusFunc options, 500 otherwise.
+// Its detail is subject to the PublicMessagePolicy, which by default only exposes UserMessage errors.
+// Its extension members are the Fields of the chain made public by WithPublicFields, redacted if Sensitive.
+//
+// Handler adapts handlers returning errors into http.Handler, writing the problem document of any error returned.
+//
+// ErrorFromResponse is the client side counterpart, reconstructing the error chain of a problem document.
+// It is a RemoteError, recording the service and status, wrapping the error built by a registered ProblemFactory.
+package xhttp
@@ -0,0 +1,86 @@
+package xhttp
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// WriteProblem writes the problem document of an error as the response, with the request path as instance.
+// The query is left out, as it may hold credentials or personal data.
+// It must be called before anything else is written to the response.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error, opts ...OptionFunc) {
+	writeProblem(w, r, err, newOptions(opts))
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, err error, opts options) {
+	p := newProblem(err, opts)
+	p.Instance = r.URL.EscapedPath()
+
+	if opts.onError != nil {
+		opts.onError(r, err, p)
+		// onError may have set any status, WriteHeader panics on invalid ones
+		p.Status = validStatus(p.Status)
+	}
+
+	// never errors
+	b, _ := p.MarshalJSON()
+
+	h := w.Header()
+	h.Set("Content-Type", ContentType)
+	h.Set("Content-Length", strconv.Itoa(len(b)))
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+
+	// the client is gone, nothing else to do
+	_, _ = w.Write(b)
+}
+
+// HandlerFunc is an http handler returning an error.
+// It should not write to the response if it returns an error, the problem document is written instead.
+type HandlerFunc func(http.ResponseWriter, *http.Request) error
+
+// Handler adapts a HandlerFunc into an http.Handler, writing the problem document of any error returned.
+// If the handler has already written to the response when returning an error, the problem document is not written,
+// but WithOnError is still called.
+func Handler(h HandlerFunc, opts ...OptionFunc) http.Handler {
+	o := newOptions(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+
+		err := h(tw, r)
+		if err == nil {
+			return
+		}
+
+		if tw.written {
+			if o.onError != nil {
+				o.onError(r, err, nil)
+			}
+			return
+		}
+
+		writeProblem(w, r, err, o)
+	})
+}
+
+// trackingWriter records if anything has been written to the response.
+type trackingWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap allows http.ResponseController to access the underlying writer.
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,159 @@
+package xhttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors/xhttp"
+)
+
+func TestHandler(t *testing.T) {
+	var logged []string
+	onError := func(r *http.Request, err error, p *xhttp.Problem) {
+		logged = append(logged, r.URL.Path+" "+xerrors.String(err))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/ok", xhttp.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = io.WriteString(w, "ok")
+		return nil
+	}, xhttp.WithOnError(onError)))
+	mux.Handle("/users/", xhttp.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		err := xerrors.Wrap("query users", errors.New("no rows"), xerrors.OmitFrame())
+		return xerrors.WithUserMessage("the user does not exist", xhttp.WithStatus(http.StatusNotFound, err), xerrors.OmitFrame())
+	}, xhttp.WithOnError(onError)))
+	mux.Handle("/partial", xhttp.Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return errors.New("late failure")
+	}, xhttp.WithOnError(onError)))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	scenarios := []struct {
+		name                string
+		path                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+		expectedLog         []string
+	}{
+		{
+			name:                "ok",
+			path:                "/ok",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "ok",
+		},
+		{
+			name:                "problem",
+			path:                "/users/7?verbose=true",
+			expectedStatus:      http.StatusNotFound,
+			expectedContentType: xhttp.ContentType,
+			expectedBody:        `{"type":"about:blank","title":"Not Found","status":404,"detail":"the user does not exist","instance":"/users/7"}`,
+			expectedLog:         []string{"/users/7 the user does not exist: status=404: query users: no rows"},
+		},
+		{
+			name:                "alreadyWritten",
+			path:                "/partial",
+			expectedStatus:      http.StatusAccepted,
+			expectedContentType: "",
+			expectedBody:        "",
+			expectedLog:         []string{"/partial late failure"},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			logged = nil
+
+			resp, err := http.Get(server.URL + scenario.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if resp.StatusCode != scenario.expectedStatus {
+				t.Fatalf("mismatched status, expected %d got %d", scenario.expectedStatus, resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != scenario.expectedContentType {
+				t.Fatalf("mismatched content type, expected %q got %q", scenario.expectedContentType, contentType)
+			}
+			if string(body) != scenario.expectedBody {
+				t.Fatalf("mismatched body, expected %s got %s", scenario.expectedBody, body)
+			}
+			if len(logged) != len(scenario.expectedLog) || (len(logged) != 0 && logged[0] != scenario.expectedLog[0]) {
+				t.Fatalf("mismatched logs, expected %q got %q", scenario.expectedLog, logged)
+			}
+		})
+	}
+}
+
+func TestWriteProblem_invalidStatus(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	w := httptest.NewRecorder()
+
+	xhttp.WriteProblem(w, r, xhttp.WithStatus(42, errors.New("bad status")))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("mismatched status, expected %d got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	w := httptest.NewRecorder()
+
+	err := xerrors.WithFields(xhttp.WithStatus(http.StatusConflict, errors.New("duplicate")), xerrors.Field{Key: "order_id", Value: 42})
+	xhttp.WriteProblem(w, r, err, xhttp.WithPublicFields("order_id"))
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("mismatched status, expected %d got %d", http.StatusConflict, w.Code)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON body: %s", err)
+	}
+
+	expected := map[string]any{
+		"type":     "about:blank",
+		"title":    "Conflict",
+		"status":   float64(409),
+		"instance": "/orders",
+		"order_id": float64(42),
+	}
+	if len(body) != len(expected) {
+		t.Fatalf("mismatched body, expected %v got %v", expected, body)
+	}
+	for k, v := range expected {
+		if body[k] != v {
+			t.Fatalf("mismatched member %q, expected %v got %v", k, v, body[k])
+		}
+	}
+}
+
+func TestWriteProblem_onErrorInvalidStatus(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	w := httptest.NewRecorder()
+
+	xhttp.WriteProblem(w, r, errors.New("failure"), xhttp.WithOnError(func(r *http.Request, err error, p *xhttp.Problem) {
+		p.Status = 42
+	}))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("mismatched status, expected %d got %d", http.StatusInternalServerError, w.Code)
+	}
+}
@@ -0,0 +1,335 @@
+package xhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+// ContentType is the media type of problem details documents.
+const ContentType = "application/problem+json"
+
+// DefaultType is the problem type of errors with no ProblemTyper in their chain.
+// As per RFC 9457, the title of such problems is the status text.
+const DefaultType = "about:blank"
+
+// Problem is an RFC 9457 problem details document.
+// Empty members are omitted from its JSON form.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+
+	// Extensions are the extension members, in order.
+	// Those with the key of a standard member are omitted from the JSON form.
+	Extensions []xerrors.Field
+}
+
+func isStandardMember(key string) bool {
+	switch key {
+	case "type", "title", "status", "detail", "instance":
+		return true
+	default:
+		return false
+	}
+}
+
+// MarshalJSON writes the standard members first, in the order of RFC 9457, followed by the extension members.
+// Extension values that can't be marshalled are written as strings, errors as xerrors.String.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+
+	first := true
+	writeMember := func(key string, value any) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		writeJSON(&buf, key)
+		buf.WriteByte(':')
+		writeJSON(&buf, value)
+	}
+
+	if p.Type != "" {
+		writeMember("type", p.Type)
+	}
+	if p.Title != "" {
+		writeMember("title", p.Title)
+	}
+	if p.Status != 0 {
+		writeMember("status", p.Status)
+	}
+	if p.Detail != "" {
+		writeMember("detail", p.Detail)
+	}
+	if p.Instance != "" {
+		writeMember("instance", p.Instance)
+	}
+	for _, field := range p.Extensions {
+		if isStandardMember(field.Key) {
+			continue
+		}
+		writeMember(field.Key, field.Value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, value any) {
+	if err, ok := value.(error); ok {
+		value = xerrors.String(err)
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(value))
+	}
+
+	buf.Write(b)
+}
+
+// StatusCoder is an error carrying the HTTP status it should be reported with.
+type StatusCoder interface {
+	xerrors.Wrapper
+	HTTPStatus() int
+}
+
+// IsStatusCoder is a helper for type casting to StatusCoder
+func IsStatusCoder(err error) bool {
+	_, ok := err.(StatusCoder)
+	return ok
+}
+
+// WithStatus wraps an error with the HTTP status it should be reported with.
+// Statuses outside 100-999, which can't be written to a response, are replaced by 500.
+// Its message is 'status=N', and being an annotation rather than a failure in itself, it never produces a FrameError.
+func WithStatus(status int, err error) error {
+	return &statusError{
+		status:   validStatus(status),
+		Wrapping: xerrors.NewWrapping(err, xerrors.OmitFrame()),
+	}
+}
+
+// validStatus returns the status if it is of three digits, as http.ResponseWriter requires, or 500 otherwise.
+func validStatus(status int) int {
+	if status < 100 || status > 999 {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+type statusError struct {
+	status int
+	xerrors.Wrapping
+}
+
+func (err *statusError) Error() string {
+	return "status=" + strconv.Itoa(err.status)
+}
+
+func (err *statusError) HTTPStatus() int {
+	return err.status
+}
+
+var _ StatusCoder = (*statusError)(nil)
+
+// ProblemTyper is an error defining the type and title of its problem document.
+type ProblemTyper interface {
+	xerrors.Wrapper
+	ProblemType() (typ, title string)
+}
+
+// WithProblemType wraps an error with the type and title of its problem document.
+// Its message is the title, and being an annotation rather than a failure in itself, it never produces a FrameError.
+func WithProblemType(typ, title string, err error) error {
+	return &problemTypeError{
+		typ:      typ,
+		title:    title,
+		Wrapping: xerrors.NewWrapping(err, xerrors.OmitFrame()),
+	}
+}
+
+type problemTypeError struct {
+	typ, title string
+	xerrors.Wrapping
+}
+
+func (err *problemTypeError) Error() string {
+	return err.title
+}
+
+func (err *problemTypeError) ProblemType() (string, string) {
+	return err.typ, err.title
+}
+
+var _ ProblemTyper = (*problemTypeError)(nil)
+
+// StatusFunc classifies a single error of the chain, returning its HTTP status and true if it has one.
+type StatusFunc = func(error) (int, bool)
+
+// StatusForType is a StatusFunc classifying all errors of type T with status.
+// T may be an interface, classifying all errors implementing it.
+func StatusForType[T error](status int) StatusFunc {
+	return func(err error) (int, bool) {
+		_, ok := err.(T)
+		return status, ok
+	}
+}
+
+// PublicMessagePolicy defines what of the error chain is exposed in the problem document, intended for API clients.
+type PublicMessagePolicy uint8
+
+const (
+	// PublicUserMessages exposes only the outermost UserMessage as detail, as per xerrors.UserString.
+	// Extension members are the public Fields of the chain, see WithPublicFields.
+	PublicUserMessages PublicMessagePolicy = iota
+	// PublicRedacted exposes the whole chain as detail, with Sensitive and Redactable errors redacted.
+	// Extension members are the public Fields of the chain, see WithPublicFields.
+	// It is intended for internal APIs only.
+	PublicRedacted
+	// PublicNone exposes nothing beyond the type, title, status and instance, not even public Fields.
+	PublicNone
+)
+
+type options struct {
+	statusFuncs []StatusFunc
+	policy      PublicMessagePolicy
+	publicKeys  []string
+	onError     func(*http.Request, error, *Problem)
+}
+
+// OptionFunc represent optional arguments to NewProblem, WriteProblem and Handler.
+type OptionFunc = func(options) options
+
+// WithStatusFuncs classifies the errors of the chain with fs, outermost error first.
+// For each error, StatusCoder takes precedence, followed by fs in order.
+func WithStatusFuncs(fs ...StatusFunc) OptionFunc {
+	return func(opts options) options {
+		opts.statusFuncs = append(opts.statusFuncs, fs...)
+		return opts
+	}
+}
+
+// WithPublicMessagePolicy overrides PublicUserMessages as the policy of what is exposed in the problem document.
+func WithPublicMessagePolicy(policy PublicMessagePolicy) OptionFunc {
+	return func(opts options) options {
+		opts.policy = policy
+		return opts
+	}
+}
+
+// WithPublicFields exposes the Fields of the chain with the given keys as extension members.
+// Fields are internal by default, none are exposed without it.
+// Sensitive fields are exposed with their value redacted, as per xerrors.RedactFields.
+func WithPublicFields(keys ...string) OptionFunc {
+	return func(opts options) options {
+		opts.publicKeys = append(opts.publicKeys, keys...)
+		return opts
+	}
+}
+
+// WithOnError is called for every error handled by Handler or WriteProblem, before its problem document is written.
+// It is intended for logging, the problem document exposing only what the PublicMessagePolicy allows.
+// The problem document may be modified, it is nil if it is not to be written, see Handler.
+func WithOnError(f func(*http.Request, error, *Problem)) OptionFunc {
+	return func(opts options) options {
+		opts.onError = f
+		return opts
+	}
+}
+
+func newOptions(opts []OptionFunc) options {
+	o := options{}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	return o
+}
+
+// StatusOf returns the HTTP status of the outermost classified error in the chain, 500 if none is.
+// Statuses outside 100-999 are also replaced by 500.
+// Errors are classified as StatusCoder, or with WithStatusFuncs, other options are ignored.
+func StatusOf(err error, opts ...OptionFunc) int {
+	return statusOf(err, newOptions(opts))
+}
+
+func statusOf(err error, opts options) int {
+	status := http.StatusInternalServerError
+
+	xerrors.Last(err, func(err error) bool {
+		if sErr, ok := err.(StatusCoder); ok {
+			status = sErr.HTTPStatus()
+			return true
+		}
+		for _, f := range opts.statusFuncs {
+			if s, ok := f(err); ok {
+				status = s
+				return true
+			}
+		}
+		return false
+	})
+
+	return validStatus(status)
+}
+
+// NewProblem builds the problem document of an error, without instance.
+func NewProblem(err error, opts ...OptionFunc) *Problem {
+	return newProblem(err, newOptions(opts))
+}
+
+func newProblem(err error, opts options) *Problem {
+	p := &Problem{
+		Type:   DefaultType,
+		Status: statusOf(err, opts),
+	}
+
+	if tErr, ok := xerrors.Last(err, isProblemTyper).(ProblemTyper); ok {
+		p.Type, p.Title = tErr.ProblemType()
+	} else {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	switch opts.policy {
+	case PublicUserMessages:
+		p.Detail = xerrors.UserString(err)
+		p.Extensions = publicFields(err, opts.publicKeys)
+	case PublicRedacted:
+		p.Detail = xerrors.RedactedString(err)
+		p.Extensions = publicFields(err, opts.publicKeys)
+	}
+
+	return p
+}
+
+// publicFields returns the Fields of the chain with the given keys, redacted.
+func publicFields(err error, keys []string) []xerrors.Field {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var fields []xerrors.Field
+	for _, field := range xerrors.Fields(err) {
+		if slices.Contains(keys, field.Key) {
+			fields = append(fields, field)
+		}
+	}
+
+	return xerrors.RedactFields(fields)
+}
+
+func isProblemTyper(err error) bool {
+	_, ok := err.(ProblemTyper)
+	return ok
+}
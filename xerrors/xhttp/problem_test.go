@@ -0,0 +1,209 @@
+package xhttp_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors/xhttp"
+)
+
+type notFoundError struct {
+	Resource string `xerr:"resource"`
+	xerrors.Wrapping
+}
+
+func (err *notFoundError) Error() string { return "not found" }
+
+type timeoutError interface {
+	error
+	Timeout() bool
+}
+
+func TestNewProblem(t *testing.T) {
+	internal := xerrors.Wrap("query users", errors.New("connection refused"), xerrors.OmitFrame())
+	notFound := &notFoundError{Resource: "user"}
+
+	scenarios := []struct {
+		name            string
+		err             error
+		opts            []xhttp.OptionFunc
+		expectedProblem *xhttp.Problem
+	}{
+		{
+			name: "internal",
+			err:  internal,
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "statusCoder",
+			err:  xerrors.WithUserMessage("the user does not exist", xhttp.WithStatus(http.StatusNotFound, internal), xerrors.OmitFrame()),
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Not Found",
+				Status: http.StatusNotFound,
+				Detail: "the user does not exist",
+			},
+		},
+		{
+			name: "outermostStatus",
+			err:  xhttp.WithStatus(http.StatusServiceUnavailable, xhttp.WithStatus(http.StatusNotFound, internal)),
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Service Unavailable",
+				Status: http.StatusServiceUnavailable,
+			},
+		},
+		{
+			name: "statusFuncs",
+			err:  xerrors.Wrap("get user", notFound, xerrors.OmitFrame()),
+			opts: []xhttp.OptionFunc{xhttp.WithStatusFuncs(xhttp.StatusForType[*notFoundError](http.StatusNotFound))},
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Not Found",
+				Status: http.StatusNotFound,
+			},
+		},
+		{
+			name: "publicFields",
+			err: xerrors.WithFields(
+				xerrors.Wrap("get user", notFound, xerrors.OmitFrame()),
+				xerrors.Field{Key: "id", Value: 7},
+				xerrors.Field{Key: "token", Value: "secret", Sensitive: true},
+				xerrors.Field{Key: "shard", Value: 3},
+			),
+			opts: []xhttp.OptionFunc{xhttp.WithPublicFields("resource", "id", "token")},
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+				Extensions: []xerrors.Field{
+					{Key: "id", Value: 7},
+					{Key: "token", Value: xerrors.RedactedPlaceholder, Sensitive: true},
+					{Key: "resource", Value: "user"},
+				},
+			},
+		},
+		{
+			name: "invalidStatus",
+			err:  xhttp.WithStatus(42, internal),
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "invalidStatusFunc",
+			err:  notFound,
+			opts: []xhttp.OptionFunc{xhttp.WithStatusFuncs(xhttp.StatusForType[*notFoundError](1000))},
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+			},
+		},
+		{
+			name: "statusFuncsInterface",
+			err:  xerrors.Wrap("read config", &os.PathError{Op: "open", Path: "config", Err: os.ErrNotExist}, xerrors.OmitFrame()),
+			opts: []xhttp.OptionFunc{xhttp.WithStatusFuncs(xhttp.StatusForType[timeoutError](http.StatusGatewayTimeout))},
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Gateway Timeout",
+				Status: http.StatusGatewayTimeout,
+			},
+		},
+		{
+			name: "problemType",
+			err:  xhttp.WithProblemType("https://example.com/probs/out-of-credit", "You do not have enough credit.", xhttp.WithStatus(http.StatusForbidden, internal)),
+			expectedProblem: &xhttp.Problem{
+				Type:   "https://example.com/probs/out-of-credit",
+				Title:  "You do not have enough credit.",
+				Status: http.StatusForbidden,
+			},
+		},
+		{
+			name: "redacted",
+			err:  xerrors.WithFields(xerrors.WrapSensitive("user secret", internal, xerrors.OmitFrame()), xerrors.Field{Key: "attempt", Value: 2}),
+			opts: []xhttp.OptionFunc{xhttp.WithPublicMessagePolicy(xhttp.PublicRedacted), xhttp.WithPublicFields("attempt")},
+			expectedProblem: &xhttp.Problem{
+				Type:       xhttp.DefaultType,
+				Title:      "Internal Server Error",
+				Status:     http.StatusInternalServerError,
+				Detail:     "attempt=2: [REDACTED]: query users: connection refused",
+				Extensions: []xerrors.Field{{Key: "attempt", Value: 2}},
+			},
+		},
+		{
+			name: "none",
+			err:  xerrors.WithUserMessage("the user does not exist", xerrors.WithFields(notFound, xerrors.Field{Key: "id", Value: 7}), xerrors.OmitFrame()),
+			opts: []xhttp.OptionFunc{xhttp.WithPublicMessagePolicy(xhttp.PublicNone), xhttp.WithPublicFields("id")},
+			expectedProblem: &xhttp.Problem{
+				Type:   xhttp.DefaultType,
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			p := xhttp.NewProblem(scenario.err, scenario.opts...)
+			if !reflect.DeepEqual(p, scenario.expectedProblem) {
+				t.Fatalf("mismatched problem, expected %+v got %+v", scenario.expectedProblem, p)
+			}
+
+			if status := xhttp.StatusOf(scenario.err, scenario.opts...); status != scenario.expectedProblem.Status {
+				t.Fatalf("mismatched StatusOf, expected %d got %d", scenario.expectedProblem.Status, status)
+			}
+		})
+	}
+}
+
+func TestProblem_MarshalJSON(t *testing.T) {
+	p := &xhttp.Problem{
+		Type:   xhttp.DefaultType,
+		Title:  "Not Found",
+		Status: http.StatusNotFound,
+		Extensions: []xerrors.Field{
+			{Key: "resource", Value: "user"},
+			{Key: "status", Value: "shadowed"},
+			{Key: "cause", Value: xerrors.Wrap("a", errors.New("b"), xerrors.OmitFrame())},
+			{Key: "invalid", Value: complex(1, 2)},
+		},
+	}
+
+	b, err := p.MarshalJSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `{"type":"about:blank","title":"Not Found","status":404,"resource":"user","cause":"a: b","invalid":"(1+2i)"}`
+	if string(b) != expected {
+		t.Fatalf("mismatched JSON, expected %s got %s", expected, b)
+	}
+}
+
+func TestProblem_MarshalJSON_value(t *testing.T) {
+	p := xhttp.Problem{Type: xhttp.DefaultType, Title: "Not Found", Status: http.StatusNotFound}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := `{"type":"about:blank","title":"Not Found","status":404}`
+	if string(b) != expected {
+		t.Fatalf("mismatched JSON, expected %s got %s", expected, b)
+	}
+}
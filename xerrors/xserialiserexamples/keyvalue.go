@@ -3,18 +3,17 @@ package xserialiserexamples
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 
 	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
 )
 
-type keyValueError interface {
-	xerrors.Wrapper
-	MultiKeyValue() [][2]string
-}
+// keyValueError is also serialised natively by xerrors.NewLogfmtSerializer.
+type keyValueError = xerrors.MultiKeyValuer
 
 func isKeyValueError(err error) bool {
 	_, ok := err.(keyValueError)
-	return ok
+	return ok || xerrors.IsFielder(err)
 }
 
 type basicKeyValueError struct {
@@ -55,6 +54,20 @@ func basicEncodeMultiKeyValue(buf *bytes.Buffer, kvs [][2]string) {
 	}
 }
 
+func basicEncodeFields(buf *bytes.Buffer, fields []xerrors.Field) {
+	for i, field := range fields {
+		if i != 0 {
+			buf.WriteString(" ")
+		}
+
+		value := field.Value
+		if err, ok := value.(error); ok {
+			value = xerrors.String(err)
+		}
+		basicEncodeKeyValue(buf, [2]string{field.Key, fmt.Sprint(value)})
+	}
+}
+
 func jsonEncodeKeyValue(buf *bytes.Buffer, jsonW *json.Encoder, kv [2]string) {
 	jsonW.Encode(kv[0])
 	buf.Truncate(buf.Len() - 1) // Encode adds \n
@@ -73,6 +86,28 @@ func jsonEncodeMultiKeyValue(buf *bytes.Buffer, kvs [][2]string) {
 	}
 }
 
+func jsonEncodeFields(buf *bytes.Buffer, fields []xerrors.Field) {
+	jsonW := json.NewEncoder(buf)
+	for i, field := range fields {
+		jsonW.Encode(field.Key)
+		buf.Truncate(buf.Len() - 1) // Encode adds \n
+		buf.WriteString(":")
+
+		value := field.Value
+		if err, ok := value.(error); ok {
+			value = xerrors.String(err)
+		}
+		if jsonW.Encode(value) != nil {
+			jsonW.Encode(nil)
+		}
+		buf.Truncate(buf.Len() - 1) // Encode adds \n
+
+		if i != len(fields)-1 {
+			buf.WriteString(",")
+		}
+	}
+}
+
 type jsonKeyValueError struct {
 	multiKeyValue [][2]string
 	xerrors.Wrapping
@@ -18,6 +18,11 @@ func (s *basicKeyValueSerializer) Keep(err error) bool {
 }
 
 func (s *basicKeyValueSerializer) CustomFormat(err error, b *bytes.Buffer) bool {
+	if fErr, ok := err.(xerrors.Fielder); ok {
+		basicEncodeFields(b, fErr.Fields())
+		return true
+	}
+
 	if kvErr, ok := err.(keyValueError); ok {
 		basicEncodeMultiKeyValue(b, kvErr.MultiKeyValue())
 		return true
@@ -49,7 +54,8 @@ func (s *basicKeyValueSerializer) Reset() {
 }
 
 // NewBasicKeyValueSerializer returns a serializer that prints non-frame errors in key-value form.
-// If the error does not implement keyValueError it prints as ?-Error().
+// Errors with fields, see xerrors.Fielder, print them as key-value pairs.
+// If the error does not implement keyValueError either it prints as ?-Error().
 // Separate wrapped errors (and separate key-value pairs) are separated by a whitespace.
 func NewBasicKeyValueSerializer() xerrors.Serializer {
 	return &basicKeyValueSerializer{
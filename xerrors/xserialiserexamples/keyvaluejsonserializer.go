@@ -27,7 +27,11 @@ func (s *jsonKeyValueSerializer) Keep(err error) bool {
 
 func (s *jsonKeyValueSerializer) CustomFormat(err error, b *bytes.Buffer) bool {
 	if s.firstEntry {
-		for auxErr := err; auxErr != nil; auxErr = xerrors.Last(xerrors.Unwrap(auxErr), s.Keep) {
+		// Revealed presents the errors as the Printer does, through xerrors.Opaque and with any truncation marker
+		for auxErr := range xerrors.Revealed(err) {
+			if !s.Keep(auxErr) {
+				continue
+			}
 			s.remainingDepth++
 			if !isKeyValueError(auxErr) {
 				s.remainingCustoms++
@@ -35,6 +39,11 @@ func (s *jsonKeyValueSerializer) CustomFormat(err error, b *bytes.Buffer) bool {
 		}
 	}
 
+	if fErr, ok := err.(xerrors.Fielder); ok {
+		jsonEncodeFields(b, fErr.Fields())
+		return true
+	}
+
 	if kvErr, ok := err.(keyValueError); ok {
 		jsonEncodeMultiKeyValue(b, kvErr.MultiKeyValue())
 		return true
@@ -80,6 +89,7 @@ func (s *jsonKeyValueSerializer) Reset() {
 
 // NewJSONKeyValueSerializer returns a serializer that prints errors in JSON.
 // For errors implementing KeyValueError, it prints them as "key":"value".
+// For errors implementing xerrors.Fielder, it prints them as "key":value, with value in its native JSON form.
 // If the error does not implement KeyValueError it prints as "unknown_N":"Error()", ... "unknown_0":"Error()".
 // Separate wrapped errors (and separate key-value pairs) are separated by comma.
 func NewJSONKeyValueSerializer() xerrors.Serializer {
@@ -2,7 +2,6 @@ package xserialiserexamples_test
 
 import (
 	"bytes"
-	"strconv"
 	"testing"
 
 	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
@@ -96,11 +95,46 @@ func TestSerializers(t *testing.T) {
 				frameOnlySerialised:     "",
 			},
 		},
+		{
+			name: "fields",
+			err: xerrors.WithFields(
+				xerrors.New("msg"),
+				xerrors.Field{Key: "user", Value: "foo bar"},
+				xerrors.Field{Key: "attempt", Value: 3},
+			),
+			expectedOutputs: serializerOutputs{
+				colonBasicSerialised:    `user="foo bar" attempt=3: msg`,
+				colonDetailSerialised:   `user="foo bar" attempt=3: msg`,
+				basicKeyValueSerialised: "user-foo bar attempt-3 ?-msg",
+				jsonKeyValueSerialised:  `{"user":"foo bar","attempt":3,"unknown_0":"msg"}`,
+				frameOnlySerialised:     "",
+			},
+		},
+		{
+			name: "opaque",
+			err: xerrors.Wrap(
+				"wrapping_msg",
+				xerrors.Opaque(xerrors.NewStaticFrame(
+					"my/pkg/foobar.myMethod",
+					"/my/home/my/gopath/src/my/pkg/foobar/myfile.go",
+					100,
+					xerrors.New("cause_msg"),
+				)),
+				xerrors.OmitFrame(),
+			),
+			expectedOutputs: serializerOutputs{
+				colonBasicSerialised:    "wrapping_msg: cause_msg",
+				colonDetailSerialised:   "wrapping_msg(foobar.myMethod:myfile.go:100): cause_msg",
+				basicKeyValueSerialised: "?-wrapping_msg ?-cause_msg",
+				jsonKeyValueSerialised:  `{"unknown_1":"wrapping_msg","unknown_0":"cause_msg"}`,
+				frameOnlySerialised:     "my/pkg/foobar.myMethod:/my/home/my/gopath/src/my/pkg/foobar/myfile.go:100",
+			},
+		},
 		{
 			name: "singleWrappedWithFrame",
 			err: xerrors.Wrap(
 				"wrapping_msg",
-				newPretendFrameError(
+				xerrors.NewStaticFrame(
 					"my/pkg/foobar.myMethod",
 					"/my/home/my/gopath/src/my/pkg/foobar/myfile.go",
 					100,
@@ -120,13 +154,13 @@ func TestSerializers(t *testing.T) {
 			name: "doubleWrappedWithFrame",
 			err: xerrors.Wrap(
 				"wrapping_msg_2",
-				newPretendFrameError(
+				xerrors.NewStaticFrame(
 					"my/pkg/foobar2.myMethod2",
 					"/my/home/my/gopath/src/my/pkg/foobar2/myfile2.go",
 					200,
 					xerrors.Wrap(
 						"wrapping_msg_1",
-						newPretendFrameError(
+						xerrors.NewStaticFrame(
 							"my/pkg/foobar1.myMethod1",
 							"/my/home/my/gopath/src/my/pkg/foobar1/myfile1.go",
 							100,
@@ -205,37 +239,22 @@ func TestSerializers(t *testing.T) {
 	}
 }
 
-type pretendFrameError struct {
-	function, file string
-	line           int
-	xerrors.Wrapping
-}
+type selfError struct{}
 
-func (err *pretendFrameError) Error() string {
-	out := ""
-	if err.function != "" {
-		out += err.function + ":"
-	}
-	if err.file != "" {
-		out += err.file + ":" + strconv.Itoa(err.line) + " "
-	}
-	if err.function != "" || err.file != "" {
-		out = out[:len(out)-1]
-	}
-	return out
-}
+func (selfError) Error() string { return "self" }
 
-func (err *pretendFrameError) FrameLocation() (string, string, int) {
-	return err.function, err.file, err.line
-}
+func (err selfError) Unwrap() error { return err }
 
-func newPretendFrameError(function, file string, line int, err error) error {
-	return &pretendFrameError{
-		function: function,
-		file:     file,
-		line:     line,
-		Wrapping: xerrors.NewWrapping(err, xerrors.OmitFrame()),
+func TestJSONKeyValueSerializer_cycle(t *testing.T) {
+	printer := xerrors.NewPrinter(xserialiserexamples.NewJSONKeyValueSerializer)
+
+	buf := bytes.Buffer{}
+	if err := printer.Write(&buf, xerrors.Wrap("wrapper", selfError{}, xerrors.OmitFrame())); err != nil {
+		t.Fatalf("error serialising error: %s", err)
 	}
-}
 
-var _ xerrors.FrameError = (*pretendFrameError)(nil)
+	expectedOut := `{"unknown_2":"wrapper","unknown_1":"self","unknown_0":"...(cycle)"}`
+	if buf.String() != expectedOut {
+		t.Fatalf("mismatched output, expected %q got %q", expectedOut, buf.String())
+	}
+}
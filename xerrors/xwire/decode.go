@@ -0,0 +1,365 @@
+package xwire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+// ErrMalformed is wrapped by the errors of Decode for input that is not a valid encoding.
+var ErrMalformed = xerrors.New("malformed error encoding")
+
+// ErrVersion is wrapped by the errors of Decode for input of a version other than Version.
+var ErrVersion = xerrors.New("unsupported error encoding version")
+
+// decodedMessage is the content of layers with no registered DecodeFunc, shared by the errors they are decoded as.
+type decodedMessage struct {
+	typ    string
+	msg    string
+	fields []xerrors.Field
+}
+
+func (m *decodedMessage) Error() string {
+	return m.msg
+}
+
+func (m *decodedMessage) Fields() []xerrors.Field {
+	return m.fields
+}
+
+// Type returns the identifier the error was encoded with.
+func (m *decodedMessage) Type() string {
+	return m.typ
+}
+
+// decodedType is Type, for the registry to tell decoded errors apart from those of other types with a Type method.
+func (m *decodedMessage) decodedType() string {
+	return m.typ
+}
+
+// decodedError is the error of layers with no registered DecodeFunc.
+// It is encoded with the same identifier, so errors can be passed on through services unaware of their type.
+type decodedError struct {
+	decodedMessage
+	xerrors.Wrapping
+}
+
+// sensitiveDecodedError is the decodedError of Sensitive layers.
+type sensitiveDecodedError struct {
+	decodedMessage
+	xerrors.Wrapping
+}
+
+func (*sensitiveDecodedError) Sensitive() {}
+
+// redactableDecodedError is the decodedError of Redactable layers.
+type redactableDecodedError struct {
+	decodedMessage
+	redacted string
+	xerrors.Wrapping
+}
+
+func (err *redactableDecodedError) RedactedError() string {
+	return err.redacted
+}
+
+var (
+	_ xerrors.Fielder    = (*decodedError)(nil)
+	_ xerrors.Sensitive  = (*sensitiveDecodedError)(nil)
+	_ xerrors.Redactable = (*redactableDecodedError)(nil)
+)
+
+type layer struct {
+	kind byte
+	// typ, msg and fields of error layers, and the redacted message of redactable error layers
+	typ, msg string
+	fields   []xerrors.Field
+	redacted string
+	// function, file and line of frame layers
+	function, file string
+	line           int
+	// time and goroutine of timed frame layers
+	time      time.Time
+	goroutine uint64
+	// omitted and flags of truncation layers
+	omitted int
+	flags   byte
+}
+
+// reader reads the encoding, recording the first failure.
+// Once failed, all reads return zero values.
+type reader struct {
+	b      []byte
+	failed bool
+}
+
+func (r *reader) fail() {
+	r.failed = true
+	r.b = nil
+}
+
+func (r *reader) byte() byte {
+	if len(r.b) == 0 {
+		r.fail()
+		return 0
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c
+}
+
+func (r *reader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) varint() int64 {
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.fail()
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) bytes() []byte {
+	n := r.uvarint()
+	if n > uint64(len(r.b)) {
+		r.fail()
+		return nil
+	}
+	b := r.b[:n:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *reader) string() string {
+	return string(r.bytes())
+}
+
+func (r *reader) value() any {
+	switch r.byte() {
+	case valueNil:
+		return nil
+	case valueString:
+		return r.string()
+	case valueBool:
+		switch r.byte() {
+		case 0:
+			return false
+		case 1:
+			return true
+		}
+	case valueInt:
+		return r.varint()
+	case valueUint:
+		return r.uvarint()
+	case valueFloat:
+		if len(r.b) < 8 {
+			break
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.b))
+		r.b = r.b[8:]
+		return v
+	case valueDuration:
+		return time.Duration(r.varint())
+	case valueTime:
+		if t, ok := r.time(); ok {
+			return t
+		}
+	case valueBytes:
+		return bytes.Clone(r.bytes())
+	}
+
+	r.fail()
+	return nil
+}
+
+// time reads a time, in UTC, returning false if it is invalid.
+func (r *reader) time() (time.Time, bool) {
+	sec, nsec := r.varint(), r.uvarint()
+	if nsec >= uint64(time.Second) {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, int64(nsec)).UTC(), true
+}
+
+// sensitive consumes the prefix of the value of Sensitive fields, if present.
+func (r *reader) sensitive() bool {
+	if len(r.b) == 0 || r.b[0] != valueSensitive {
+		return false
+	}
+	r.b = r.b[1:]
+	return true
+}
+
+func (r *reader) layer() layer {
+	l := layer{kind: r.byte()}
+
+	switch l.kind {
+	case kindEnd:
+	case kindError, kindSensitiveError, kindRedactableError:
+		l.typ, l.msg = r.string(), r.string()
+		if l.kind == kindRedactableError {
+			l.redacted = r.string()
+		}
+
+		n := r.uvarint()
+		// every field takes at least two bytes, an empty key and a nil value
+		if n > uint64(len(r.b))/2 {
+			r.fail()
+			break
+		}
+
+		if n != 0 {
+			l.fields = make([]xerrors.Field, n)
+		}
+		for i := range l.fields {
+			l.fields[i] = xerrors.Field{Key: r.string(), Sensitive: r.sensitive(), Value: r.value()}
+		}
+	case kindFrame, kindTimedFrame, kindSpawnFrame:
+		l.function, l.file = r.string(), r.string()
+
+		line := r.uvarint()
+		if line > math.MaxInt32 {
+			r.fail()
+			break
+		}
+		l.line = int(line)
+
+		if l.kind == kindTimedFrame {
+			var ok bool
+			if l.time, ok = r.time(); !ok {
+				r.fail()
+				break
+			}
+			l.goroutine = r.uvarint()
+		}
+	case kindTruncation:
+		omitted := r.uvarint()
+		if omitted > math.MaxInt32 {
+			r.fail()
+			break
+		}
+		l.omitted = int(omitted)
+
+		if l.flags = r.byte(); l.flags&^(truncationMore|truncationCycle) != 0 {
+			r.fail()
+		}
+	default:
+		r.fail()
+	}
+
+	return l
+}
+
+// Decoded is the result of Decode.
+type Decoded struct {
+	// Err is the decoded error chain, nil for empty input.
+	// If the encoded chain was truncated, its innermost error is the xerrors.Truncation marker.
+	Err error
+	// Truncated is set if the encoded chain was truncated, by the depth of the Printer or a cycle.
+	Truncated bool
+}
+
+// Decode reconstructs the error chain of an encoding, the reverse of Encode.
+// The errors of registered types are rebuilt by their DecodeFunc, others as errors with the same message and fields,
+// which are xerrors.Sensitive or xerrors.Redactable if the encoded errors were.
+// Frames are rebuilt with xerrors.NewStaticFrame, NewStaticTimedFrame or NewStaticSpawnFrame,
+// and the truncation marker with xerrors.NewTruncation.
+// Empty input decodes to a nil error, invalid input returns an error wrapping ErrMalformed or ErrVersion.
+// Input of more layers than the errors of a walk, see xerrors.MaxErrors, is invalid.
+func Decode(b []byte, opts ...OptionFunc) (*Decoded, error) {
+	if len(b) == 0 {
+		return &Decoded{}, nil
+	}
+
+	o := newOptions(opts)
+
+	if len(b) < len(header) || b[0] != header[0] || b[1] != header[1] {
+		return nil, xerrors.Wrap("invalid header", ErrMalformed, xerrors.OmitFrame())
+	}
+	if b[2] != Version {
+		return nil, xerrors.Wrap("version "+strconv.Itoa(int(b[2])), ErrVersion, xerrors.OmitFrame())
+	}
+
+	r := reader{b: b[len(header):]}
+	// as many errors as Printers walk, and the truncation marker
+	maxLayers := xerrors.MaxErrors() + 1
+
+	var layers []layer
+	for {
+		l := r.layer()
+		if r.failed {
+			return nil, xerrors.Wrap("invalid layer "+strconv.Itoa(len(layers)), ErrMalformed, xerrors.OmitFrame())
+		}
+		if l.kind == kindEnd {
+			break
+		}
+
+		if len(layers) == maxLayers {
+			return nil, xerrors.Wrap("too many layers", ErrMalformed, xerrors.OmitFrame())
+		}
+		if len(layers) != 0 && layers[len(layers)-1].kind == kindTruncation {
+			return nil, xerrors.Wrap("layer after truncation", ErrMalformed, xerrors.OmitFrame())
+		}
+		layers = append(layers, l)
+	}
+
+	if len(r.b) != 0 {
+		return nil, xerrors.Wrap("trailing data", ErrMalformed, xerrors.OmitFrame())
+	}
+	if len(layers) == 0 {
+		return nil, xerrors.Wrap("no layers", ErrMalformed, xerrors.OmitFrame())
+	}
+
+	var err error
+	for i := len(layers) - 1; i >= 0; i-- {
+		err = layers[i].build(o.registry, err)
+	}
+
+	return &Decoded{
+		Err:       err,
+		Truncated: layers[len(layers)-1].kind == kindTruncation,
+	}, nil
+}
+
+func (l *layer) build(registry *Registry, err error) error {
+	switch l.kind {
+	case kindFrame:
+		return xerrors.NewStaticFrame(l.function, l.file, l.line, err)
+	case kindTimedFrame:
+		return xerrors.NewStaticTimedFrame(l.function, l.file, l.line, l.time, l.goroutine, err)
+	case kindSpawnFrame:
+		return xerrors.NewStaticSpawnFrame(l.function, l.file, l.line, err)
+	case kindTruncation:
+		return xerrors.NewTruncation(l.omitted, l.flags&truncationMore != 0, l.flags&truncationCycle != 0)
+	}
+
+	if decode, ok := registry.decoder(l.typ); ok {
+		if dErr := decode(l.msg, l.fields, err); dErr != nil {
+			return dErr
+		}
+	}
+
+	m := decodedMessage{typ: l.typ, msg: l.msg, fields: l.fields}
+	w := xerrors.NewWrapping(err, xerrors.OmitFrame())
+
+	switch l.kind {
+	case kindSensitiveError:
+		return &sensitiveDecodedError{decodedMessage: m, Wrapping: w}
+	case kindRedactableError:
+		return &redactableDecodedError{decodedMessage: m, redacted: l.redacted, Wrapping: w}
+	}
+	return &decodedError{decodedMessage: m, Wrapping: w}
+}
@@ -0,0 +1,153 @@
+package xwire_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors/xwire"
+)
+
+func TestDecode_malformed(t *testing.T) {
+	valid := xwire.Encode(xerrors.Wrap("outer", errors.New("cause")))
+
+	scenarios := []struct {
+		name        string
+		in          []byte
+		expectedErr error
+	}{
+		{
+			name:        "header",
+			in:          []byte("xy\x01"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "shortHeader",
+			in:          []byte("x"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "version",
+			in:          []byte("xe\x02\x00"),
+			expectedErr: xwire.ErrVersion,
+		},
+		{
+			name:        "noLayers",
+			in:          []byte("xe\x01\x00"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "truncated",
+			in:          valid[:len(valid)-3],
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "noEnd",
+			in:          valid[:len(valid)-1],
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "trailing",
+			in:          append(append([]byte{}, valid...), 0),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "unknownKind",
+			in:          []byte("xe\x01\x09\x00"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "stringTooLong",
+			in:          []byte("xe\x01\x01\xff\x01a\x00"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "tooManyFields",
+			in:          []byte("xe\x01\x01\x00\x00\xff\xff\x03\x00"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "invalidFrameTime",
+			in:          []byte("xe\x01\x03\x00\x00\x00\x00\xff\xff\xff\xff\x0f\x00\x00"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "invalidTruncationFlags",
+			in:          []byte("xe\x01\x05\x02\x04\x00"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "layerAfterTruncation",
+			in:          []byte("xe\x01\x05\x02\x00\x01\x00\x00\x00\x00"),
+			expectedErr: xwire.ErrMalformed,
+		},
+		{
+			name:        "invalidBool",
+			in:          []byte("xe\x01\x01\x00\x00\x01\x00\x02\x02\x00"),
+			expectedErr: xwire.ErrMalformed,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			result, err := xwire.Decode(scenario.in)
+			if result != nil {
+				t.Fatalf("unexpected decoded error: %s", xerrors.String(result.Err))
+			}
+			if !xerrors.Contains(err, scenario.expectedErr) {
+				t.Fatalf("expected an error containing %q, got %q", xerrors.String(scenario.expectedErr), xerrors.String(err))
+			}
+		})
+	}
+}
+
+func TestDecode_tooManyLayers(t *testing.T) {
+	defer xerrors.SetMaxDepth(0)
+	xerrors.SetMaxDepth(4)
+
+	in := []byte("xe\x01")
+	for i := 0; i < xerrors.MaxErrors()+2; i++ {
+		in = append(in, 1, 0, 0, 0)
+	}
+	in = append(in, 0)
+
+	if _, err := xwire.Decode(in); !xerrors.Contains(err, xwire.ErrMalformed) {
+		t.Fatalf("expected an error containing ErrMalformed, got %q", xerrors.String(err))
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(xwire.Encode(xerrors.Wrap("outer", xerrors.Wrap("inner", errors.New("cause")))))
+	f.Add(xwire.Encode(xerrors.Wrap("outer", &quotaError{Limit: 10})))
+	f.Add(xwire.Encode(xerrors.WithFields(
+		errors.New("cause"),
+		xerrors.Field{Key: "string", Value: "s"},
+		xerrors.Field{Key: "int", Value: -7},
+		xerrors.Field{Key: "float", Value: 1.5},
+		xerrors.Field{Key: "time", Value: time.Unix(1, 2)},
+		xerrors.Field{Key: "bytes", Value: []byte{0, 1}},
+	)))
+	f.Add([]byte("xe\x01\x00"))
+	f.Add([]byte("xe\x01\x01\x00\x00\x00\x05\x02\x01\x00"))
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		result, err := xwire.Decode(in)
+		if err != nil || result.Err == nil {
+			return
+		}
+
+		// the encoding of decoded errors is canonical, so it must survive a further round trip
+		b := xwire.Encode(result.Err)
+		redecoded, err := xwire.Decode(b)
+		if err != nil {
+			t.Fatalf("failed to decode re-encoded error: %s", xerrors.String(err))
+		}
+		if reencoded := xwire.Encode(redecoded.Err); !bytes.Equal(reencoded, b) {
+			t.Fatalf("mismatched re-encoding, expected %x got %x", b, reencoded)
+		}
+	})
+}
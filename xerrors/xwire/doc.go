@@ -0,0 +1,33 @@
+// Package xwire is a compact, versioned binary encoding of xerrors chains, for RPC between services.
+//
+// Each error in the chain is encoded as a layer holding its type identifier, message and fields.
+// FrameErrors are encoded as their function, file and line, which unlike program counters are portable.
+// Those of TimedFrameErrors also hold their time and goroutine (kind 3), SpawnFrameErrors have their own kind (4).
+// Errors wrapping multiple errors are encoded flattened, depth-first.
+// The marker of chains truncated by the Printer, an xerrors.Truncation, has its own kind (5), so it is never mistaken for an error.
+// Sensitive (kind 6) and Redactable (kind 7) errors remain so once decoded, the latter also holding its redacted message.
+//
+// The encoder is a Serializer, see NewSerializer, and Encode is a helper for it.
+// Decode reconstructs the chain, with the errors of registered types rebuilt by their DecodeFunc.
+// Its Decoded result reports whether the chain was truncated.
+//
+// The encoding, for Version 1, is:
+//
+//	header: 'x' 'e' version
+//	layer:  kind=1 type:string msg:string nfields:uvarint (key:string [9] value)*
+//	        kind=2 function:string file:string line:uvarint
+//	        kind=3 function:string file:string line:uvarint time goroutine:uvarint
+//	        kind=4 function:string file:string line:uvarint
+//	        kind=5 omitted:uvarint flags:byte (1 more | 2 cycle), last only
+//	        kind=6 type:string msg:string nfields:uvarint (key:string [9] value)*
+//	        kind=7 type:string msg:string redacted:string nfields:uvarint (key:string [9] value)*
+//	end:    kind=0
+//	string: len:uvarint bytes
+//	value:  0 (nil) | 1 string | 2 bool:byte | 3 int:varint | 4 uint:uvarint | 5 float:float64 |
+//	        6 duration:varint | 7 time | 8 bytes:string
+//	time:   unix seconds:varint nanoseconds:uvarint
+//
+// Varints are those of encoding/binary, and float64 is little-endian IEEE 754.
+// Field values of other types are encoded as strings, and ints, uints and floats of all sizes as 64 bits.
+// The values of Sensitive fields are prefixed by 9, so they remain Sensitive once decoded.
+package xwire
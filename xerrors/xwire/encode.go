@@ -0,0 +1,332 @@
+package xwire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+// Version is the version of the encoding written by the encoder, and the only one read by the decoder.
+const Version = 1
+
+var (
+	header = []byte{'x', 'e', Version}
+	end    = []byte{kindEnd}
+)
+
+const (
+	kindEnd byte = iota
+	kindError
+	kindFrame
+	kindTimedFrame
+	kindSpawnFrame
+	kindTruncation
+	kindSensitiveError
+	kindRedactableError
+)
+
+// flags of truncation layers
+const (
+	truncationMore byte = 1 << iota
+	truncationCycle
+)
+
+const (
+	valueNil byte = iota
+	valueString
+	valueBool
+	valueInt
+	valueUint
+	valueFloat
+	valueDuration
+	valueTime
+	valueBytes
+	// valueSensitive prefixes the value of Sensitive fields
+	valueSensitive
+)
+
+type options struct {
+	registry *Registry
+}
+
+// OptionFunc represent optional arguments to NewSerializer and Decode.
+type OptionFunc = func(options) options
+
+// WithRegistry overrides DefaultRegistry as the registry of error types.
+func WithRegistry(r *Registry) OptionFunc {
+	return func(opts options) options {
+		opts.registry = r
+		return opts
+	}
+}
+
+func newOptions(opts []OptionFunc) options {
+	o := options{registry: defaultRegistry}
+	for _, opt := range opts {
+		o = opt(o)
+	}
+	return o
+}
+
+type serializer struct {
+	registry *Registry
+	written  bool
+}
+
+func (s *serializer) Keep(error) bool {
+	return true
+}
+
+func (s *serializer) CustomFormat(err error, buf *bytes.Buffer) bool {
+	if tErr, ok := err.(xerrors.Truncation); ok {
+		omitted, more, cycle := tErr.Truncation()
+		if omitted < 0 {
+			omitted = 0
+		}
+
+		var flags byte
+		if more {
+			flags |= truncationMore
+		}
+		if cycle {
+			flags |= truncationCycle
+		}
+
+		buf.WriteByte(kindTruncation)
+		writeUvarint(buf, uint64(omitted))
+		buf.WriteByte(flags)
+		return true
+	}
+
+	if frameErr, ok := err.(xerrors.FrameError); ok {
+		timedErr, isTimed := err.(xerrors.TimedFrameError)
+		switch {
+		case isTimed:
+			buf.WriteByte(kindTimedFrame)
+		case xerrors.IsSpawnFrameError(err):
+			buf.WriteByte(kindSpawnFrame)
+		default:
+			buf.WriteByte(kindFrame)
+		}
+
+		function, file, line := frameErr.FrameLocation()
+		if line < 0 {
+			line = 0
+		}
+		writeString(buf, function)
+		writeString(buf, file)
+		writeUvarint(buf, uint64(line))
+
+		if isTimed {
+			writeTime(buf, timedErr.FrameTime())
+			writeUvarint(buf, timedErr.FrameGoroutine())
+		}
+		return true
+	}
+
+	var fields []xerrors.Field
+	if fErr, ok := err.(xerrors.Fielder); ok {
+		fields = fErr.Fields()
+	} else {
+		fields = xerrors.TaggedFields(err)
+	}
+
+	// Sensitive and Redactable errors have their own kinds, so they remain so once decoded
+	sensitive := xerrors.IsSensitive(err)
+	rErr, redactable := err.(xerrors.Redactable)
+	redactable = redactable && !sensitive
+	switch {
+	case sensitive:
+		buf.WriteByte(kindSensitiveError)
+	case redactable:
+		buf.WriteByte(kindRedactableError)
+	default:
+		buf.WriteByte(kindError)
+	}
+
+	writeString(buf, s.registry.id(err))
+	writeString(buf, err.Error())
+	if redactable {
+		writeString(buf, rErr.RedactedError())
+	}
+	writeUvarint(buf, uint64(len(fields)))
+	for _, field := range fields {
+		writeString(buf, field.Key)
+		if field.Sensitive {
+			buf.WriteByte(valueSensitive)
+		}
+		writeValue(buf, field.Value)
+	}
+
+	return true
+}
+
+func (s *serializer) Append(w io.Writer, b []byte) error {
+	if !s.written {
+		s.written = true
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(b)
+	return err
+}
+
+// Flush writes the end of the encoding, if any error was appended.
+func (s *serializer) Flush(w io.Writer) error {
+	if !s.written {
+		return nil
+	}
+
+	_, err := w.Write(end)
+	return err
+}
+
+func (s *serializer) Reset() {
+	s.written = false
+}
+
+// NewSerializer provides a serializer writing the binary encoding of errors.
+// Nothing is written for nil errors.
+// Printers using it must not be created with truncation options, which would corrupt the encoding.
+func NewSerializer(opts ...OptionFunc) xerrors.Serializer {
+	return &serializer{
+		registry: newOptions(opts).registry,
+	}
+}
+
+var (
+	defaultPrinter = xerrors.NewPrinter(func() xerrors.Serializer { return NewSerializer() })
+
+	defaultEncodeBufferPool = sync.Pool{
+		New: func() interface{} {
+			return &bytes.Buffer{}
+		},
+	}
+)
+
+// Encode returns the binary encoding of err, with the types of DefaultRegistry.
+// It returns nil for nil errors.
+func Encode(err error) []byte {
+	buf := defaultEncodeBufferPool.Get().(*bytes.Buffer)
+
+	// never errors
+	_ = defaultPrinter.Write(buf, err)
+
+	var out []byte
+	if buf.Len() != 0 {
+		out = make([]byte, buf.Len())
+		copy(out, buf.Bytes())
+	}
+
+	buf.Reset()
+	defaultEncodeBufferPool.Put(buf)
+
+	return out
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var aux [binary.MaxVarintLen64]byte
+	buf.Write(binary.AppendUvarint(aux[:0], v))
+}
+
+func writeVarint(buf *bytes.Buffer, v int64) {
+	var aux [binary.MaxVarintLen64]byte
+	buf.Write(binary.AppendVarint(aux[:0], v))
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	writeUvarint(buf, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+// writeValue writes the field value with its type.
+// Values of types other than those of the value constants are written as strings, errors as xerrors.String.
+func writeValue(buf *bytes.Buffer, value any) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(valueNil)
+	case string:
+		buf.WriteByte(valueString)
+		writeString(buf, v)
+	case bool:
+		buf.WriteByte(valueBool)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case int:
+		writeInt(buf, int64(v))
+	case int8:
+		writeInt(buf, int64(v))
+	case int16:
+		writeInt(buf, int64(v))
+	case int32:
+		writeInt(buf, int64(v))
+	case int64:
+		writeInt(buf, v)
+	case uint:
+		writeUint(buf, uint64(v))
+	case uint8:
+		writeUint(buf, uint64(v))
+	case uint16:
+		writeUint(buf, uint64(v))
+	case uint32:
+		writeUint(buf, uint64(v))
+	case uint64:
+		writeUint(buf, v)
+	case float32:
+		writeFloat(buf, float64(v))
+	case float64:
+		writeFloat(buf, v)
+	case time.Duration:
+		buf.WriteByte(valueDuration)
+		writeVarint(buf, int64(v))
+	case time.Time:
+		buf.WriteByte(valueTime)
+		writeTime(buf, v)
+	case []byte:
+		buf.WriteByte(valueBytes)
+		writeUvarint(buf, uint64(len(v)))
+		buf.Write(v)
+	case error:
+		buf.WriteByte(valueString)
+		writeString(buf, xerrors.String(v))
+	case fmt.Stringer:
+		buf.WriteByte(valueString)
+		writeString(buf, v.String())
+	default:
+		buf.WriteByte(valueString)
+		writeString(buf, fmt.Sprint(v))
+	}
+}
+
+func writeTime(buf *bytes.Buffer, t time.Time) {
+	writeVarint(buf, t.Unix())
+	writeUvarint(buf, uint64(t.Nanosecond()))
+}
+
+func writeInt(buf *bytes.Buffer, v int64) {
+	buf.WriteByte(valueInt)
+	writeVarint(buf, v)
+}
+
+func writeUint(buf *bytes.Buffer, v uint64) {
+	buf.WriteByte(valueUint)
+	writeUvarint(buf, v)
+}
+
+func writeFloat(buf *bytes.Buffer, v float64) {
+	var aux [8]byte
+	binary.LittleEndian.PutUint64(aux[:], math.Float64bits(v))
+	buf.WriteByte(valueFloat)
+	buf.Write(aux[:])
+}
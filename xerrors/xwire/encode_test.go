@@ -0,0 +1,382 @@
+package xwire_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors/xwire"
+)
+
+type quotaError struct {
+	Limit int64 `xerr:"limit"`
+	xerrors.Wrapping
+}
+
+func (err *quotaError) Error() string { return "quota exceeded" }
+
+func init() {
+	xwire.Register[*quotaError]("example.quota", func(msg string, fields []xerrors.Field, err error) error {
+		qErr := &quotaError{Wrapping: xerrors.NewWrapping(err, xerrors.OmitFrame())}
+		for _, field := range fields {
+			if field.Key == "limit" {
+				qErr.Limit, _ = field.Value.(int64)
+			}
+		}
+		return qErr
+	})
+}
+
+func TestEncode_roundTrip(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 123, time.FixedZone("CEST", 2*60*60))
+
+	scenarios := []struct {
+		name           string
+		err            error
+		expectedFields []xerrors.Field
+	}{
+		{
+			name: "frames",
+			err:  xerrors.Wrap("outer", xerrors.Wrap("inner", errors.New("cause"))),
+		},
+		{
+			name: "registered",
+			err:  xerrors.Wrap("outer", &quotaError{Limit: 10}),
+			expectedFields: []xerrors.Field{
+				{Key: "limit", Value: int64(10)},
+			},
+		},
+		{
+			name: "fields",
+			err: xerrors.WithFields(
+				errors.New("cause"),
+				xerrors.Field{Key: "nil", Value: nil},
+				xerrors.Field{Key: "string", Value: "s"},
+				xerrors.Field{Key: "bool", Value: true},
+				xerrors.Field{Key: "int", Value: -7},
+				xerrors.Field{Key: "uint", Value: uint8(7)},
+				xerrors.Field{Key: "float", Value: float32(1.5)},
+				xerrors.Field{Key: "duration", Value: time.Second},
+				xerrors.Field{Key: "time", Value: at},
+				xerrors.Field{Key: "bytes", Value: []byte{0, 1}},
+				xerrors.Field{Key: "error", Value: xerrors.Wrap("a", errors.New("b"), xerrors.OmitFrame())},
+				xerrors.Field{Key: "other", Value: struct{ A int }{1}},
+			),
+			expectedFields: []xerrors.Field{
+				{Key: "nil", Value: nil},
+				{Key: "string", Value: "s"},
+				{Key: "bool", Value: true},
+				{Key: "int", Value: int64(-7)},
+				{Key: "uint", Value: uint64(7)},
+				{Key: "float", Value: float64(1.5)},
+				{Key: "duration", Value: time.Second},
+				{Key: "time", Value: at.UTC()},
+				{Key: "bytes", Value: []byte{0, 1}},
+				{Key: "error", Value: "a: b"},
+				{Key: "other", Value: "{1}"},
+			},
+		},
+		{
+			name: "timedAndSpawnFrames",
+			err:  xerrors.NewSpawnSite().Wrap(xerrors.Wrap("inner", errors.New("cause"), xerrors.RecordTime())),
+		},
+		{
+			name: "sensitive",
+			err:  xerrors.WithFields(errors.New("cause"), xerrors.Field{Key: "token", Value: "secret", Sensitive: true}),
+			expectedFields: []xerrors.Field{
+				{Key: "token", Value: "secret", Sensitive: true},
+			},
+		},
+		{
+			name: "opaque",
+			err:  xerrors.Wrap("outer", xerrors.Opaque(xerrors.Wrap("hidden", errors.New("cause"), xerrors.OmitFrame())), xerrors.OmitFrame()),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			b := xwire.Encode(scenario.err)
+
+			result, err := xwire.Decode(b)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", xerrors.String(err))
+			}
+			if result.Truncated {
+				t.Fatal("unexpected truncation")
+			}
+			decoded := result.Err
+
+			if expected, got := xerrors.DetailString(scenario.err), xerrors.DetailString(decoded); got != expected {
+				t.Fatalf("mismatched DetailString, expected %q got %q", expected, got)
+			}
+
+			if fields := xerrors.Fields(decoded); !reflect.DeepEqual(fields, scenario.expectedFields) {
+				t.Fatalf("mismatched Fields, expected %v got %v", scenario.expectedFields, fields)
+			}
+
+			for _, typed := range []func(error) bool{xerrors.IsTimedFrameError, xerrors.IsSpawnFrameError} {
+				if (xerrors.Last(decoded, typed) == nil) != (xerrors.Last(scenario.err, typed) == nil) {
+					t.Fatalf("mismatched frame types, expected %s got %s", xerrors.DetailString(scenario.err), xerrors.DetailString(decoded))
+				}
+			}
+
+			if reencoded := xwire.Encode(decoded); !bytes.Equal(reencoded, b) {
+				t.Fatalf("mismatched re-encoding, expected %x got %x", b, reencoded)
+			}
+		})
+	}
+
+	t.Run("typed", func(t *testing.T) {
+		result, err := xwire.Decode(xwire.Encode(xerrors.Wrap("outer", &quotaError{Limit: 10})))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", xerrors.String(err))
+		}
+
+		qErr, ok := xerrors.Last(result.Err, func(err error) bool {
+			_, ok := err.(*quotaError)
+			return ok
+		}).(*quotaError)
+		if !ok || qErr.Limit != 10 {
+			t.Fatalf("expected a quotaError with limit 10, got %s", xerrors.DetailString(result.Err))
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		if b := xwire.Encode(nil); b != nil {
+			t.Fatalf("expected no encoding, got %x", b)
+		}
+
+		result, err := xwire.Decode(nil)
+		if err != nil || result.Err != nil || result.Truncated {
+			t.Fatalf("expected nil errors, got %+v %v", result, err)
+		}
+	})
+}
+
+func TestNewSerializer_registry(t *testing.T) {
+	r := xwire.NewRegistry()
+	xwire.RegisterTo[*quotaError](r, "other.quota", func(msg string, fields []xerrors.Field, err error) error {
+		return xerrors.Wrap("rebuilt quota", err, xerrors.OmitFrame())
+	})
+
+	printer := xerrors.NewPrinter(func() xerrors.Serializer { return xwire.NewSerializer(xwire.WithRegistry(r)) })
+	buf := bytes.Buffer{}
+	if err := printer.Write(&buf, &quotaError{Limit: 10}); err != nil {
+		t.Fatalf("error serialising error: %s", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("other.quota")) {
+		t.Fatalf("expected the registry's identifier in the encoding, got %q", buf.Bytes())
+	}
+
+	result, err := xwire.Decode(buf.Bytes(), xwire.WithRegistry(r))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", xerrors.String(err))
+	}
+	if expected := "rebuilt quota"; xerrors.String(result.Err) != expected {
+		t.Fatalf("mismatched String, expected %q got %q", expected, xerrors.String(result.Err))
+	}
+
+	// the default registry does not know the identifier, the error is decoded as a generic one
+	result, err = xwire.Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", xerrors.String(err))
+	}
+	if expected := "quota exceeded"; xerrors.String(result.Err) != expected {
+		t.Fatalf("mismatched String, expected %q got %q", expected, xerrors.String(result.Err))
+	}
+}
+
+func TestEncode_truncation(t *testing.T) {
+	// an error whose message is that of a truncation marker
+	impostor := xerrors.Wrap("outer", errors.New("...(2 more)"), xerrors.OmitFrame())
+
+	deep := errors.New("cause")
+	for i := 0; i < 5; i++ {
+		deep = xerrors.Wrap("wrapper", deep, xerrors.OmitFrame())
+	}
+
+	scenarios := []struct {
+		name              string
+		err               error
+		expectedTruncated bool
+	}{
+		{
+			name:              "impostor",
+			err:               impostor,
+			expectedTruncated: false,
+		},
+		{
+			name:              "deep",
+			err:               deep,
+			expectedTruncated: true,
+		},
+		{
+			name:              "cycle",
+			err:               xerrors.Wrap("outer", selfError{}, xerrors.OmitFrame()),
+			expectedTruncated: true,
+		},
+	}
+
+	printer := xerrors.NewPrinter(func() xerrors.Serializer { return xwire.NewSerializer() }, xerrors.WithMaxDepth(3))
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := printer.Write(&buf, scenario.err); err != nil {
+				t.Fatalf("error serialising error: %s", err)
+			}
+
+			result, err := xwire.Decode(buf.Bytes())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", xerrors.String(err))
+			}
+			if result.Truncated != scenario.expectedTruncated {
+				t.Fatalf("mismatched Truncated, expected %t got %t", scenario.expectedTruncated, result.Truncated)
+			}
+			if isTruncation := xerrors.IsTruncation(xerrors.Cause(result.Err)); isTruncation != scenario.expectedTruncated {
+				t.Fatalf("mismatched innermost error, expected a truncation %t got %t", scenario.expectedTruncated, isTruncation)
+			}
+
+			expectedBuf := bytes.Buffer{}
+			if err := xerrors.NewPrinter(xerrors.NewColonBasicSerializer, xerrors.WithMaxDepth(3)).Write(&expectedBuf, scenario.err); err != nil {
+				t.Fatalf("error serialising error: %s", err)
+			}
+			if got := xerrors.String(result.Err); got != expectedBuf.String() {
+				t.Fatalf("mismatched String, expected %q got %q", expectedBuf.String(), got)
+			}
+
+			if reencoded := xwire.Encode(result.Err); !bytes.Equal(reencoded, buf.Bytes()) {
+				t.Fatalf("mismatched re-encoding, expected %x got %x", buf.Bytes(), reencoded)
+			}
+		})
+	}
+}
+
+type selfError struct{}
+
+func (selfError) Error() string { return "self" }
+
+func (err selfError) Unwrap() error { return err }
+
+func TestEncode_wideTree(t *testing.T) {
+	children := make([]error, 1500)
+	for i := range children {
+		children[i] = errors.New("child")
+	}
+
+	b := xwire.Encode(errors.Join(children...))
+
+	result, err := xwire.Decode(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", xerrors.String(err))
+	}
+	if result.Truncated {
+		t.Fatal("unexpected truncation")
+	}
+
+	// the tree is decoded flattened, beyond MaxDepth, so it is counted unwrapping it directly
+	layers := 0
+	for err := result.Err; err != nil; err = xerrors.Unwrap(err) {
+		layers++
+	}
+	if expected := len(children) + 1; layers != expected {
+		t.Fatalf("mismatched layers, expected %d got %d", expected, layers)
+	}
+}
+
+func TestEncode_redaction(t *testing.T) {
+	scenarios := []struct {
+		name                string
+		err                 error
+		expectedRedactedOut string
+	}{
+		{
+			name:                "sensitive",
+			err:                 xerrors.WrapSensitive("token abc", errors.New("cause"), xerrors.OmitFrame()),
+			expectedRedactedOut: "[REDACTED]: cause",
+		},
+		{
+			name:                "redactable",
+			err:                 xerrors.WrapRedactable("token abc", []string{"abc"}, errors.New("cause"), xerrors.OmitFrame()),
+			expectedRedactedOut: "token [REDACTED]: cause",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.name, func(t *testing.T) {
+			b := xwire.Encode(scenario.err)
+
+			result, err := xwire.Decode(b)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", xerrors.String(err))
+			}
+
+			if out := xerrors.String(result.Err); out != xerrors.String(scenario.err) {
+				t.Fatalf("mismatched String, expected %q got %q", xerrors.String(scenario.err), out)
+			}
+			if out := xerrors.RedactedString(result.Err); out != scenario.expectedRedactedOut {
+				t.Fatalf("mismatched RedactedString, expected %q got %q", scenario.expectedRedactedOut, out)
+			}
+
+			if reencoded := xwire.Encode(result.Err); !bytes.Equal(reencoded, b) {
+				t.Fatalf("mismatched re-encoding, expected %x got %x", b, reencoded)
+			}
+		})
+	}
+}
+
+type otherQuotaError struct {
+	xerrors.Wrapping
+}
+
+func (err *otherQuotaError) Error() string { return "other quota exceeded" }
+
+func TestRegisterTo_replace(t *testing.T) {
+	r := xwire.NewRegistry()
+	decode := func(msg string, fields []xerrors.Field, err error) error {
+		return xerrors.Wrap("rebuilt", err, xerrors.OmitFrame())
+	}
+
+	xwire.RegisterTo[*quotaError](r, "first.quota", decode)
+	xwire.RegisterTo[*quotaError](r, "second.quota", decode)
+	xwire.RegisterTo[*otherQuotaError](r, "second.quota", decode)
+
+	encode := func(err error) []byte {
+		buf := bytes.Buffer{}
+		printer := xerrors.NewPrinter(func() xerrors.Serializer { return xwire.NewSerializer(xwire.WithRegistry(r)) })
+		if wErr := printer.Write(&buf, err); wErr != nil {
+			t.Fatalf("error serialising error: %s", wErr)
+		}
+		return buf.Bytes()
+	}
+
+	// the type's registration was replaced twice, it is encoded with its Go type name
+	if b := encode(&quotaError{}); !bytes.Contains(b, []byte("*xwire_test.quotaError")) {
+		t.Fatalf("expected the Go type name in the encoding, got %q", b)
+	}
+
+	// the first identifier is not decoded with its stale DecodeFunc
+	b := encode(&quotaError{})
+	b = bytes.Replace(b, []byte("\x16*xwire_test.quotaError"), []byte("\x0bfirst.quota"), 1)
+	result, err := xwire.Decode(b, xwire.WithRegistry(r))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", xerrors.String(err))
+	}
+	if expected := "quota exceeded"; xerrors.String(result.Err) != expected {
+		t.Fatalf("mismatched String, expected %q got %q", expected, xerrors.String(result.Err))
+	}
+
+	if b := encode(&otherQuotaError{}); !bytes.Contains(b, []byte("second.quota")) {
+		t.Fatalf("expected the registry's identifier in the encoding, got %q", b)
+	}
+}
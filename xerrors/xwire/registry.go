@@ -0,0 +1,91 @@
+package xwire
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/JavierZunzunegui/Go2_error_values_counter_proposal/xerrors"
+)
+
+// DecodeFunc rebuilds an error of a registered type from its decoded message and fields, wrapping err.
+type DecodeFunc = func(msg string, fields []xerrors.Field, err error) error
+
+// Registry maps error types to the identifiers they are encoded with, and those to their DecodeFunc.
+// Unregistered types are encoded with their Go type name, and decoded as generic errors with the same message and
+// fields. It is safe for concurrent use.
+type Registry struct {
+	mux      sync.RWMutex
+	ids      map[reflect.Type]string
+	types    map[string]reflect.Type
+	decoders map[string]DecodeFunc
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the global registry, to which Register adds types.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// NewRegistry initialises an empty registry, for use WithRegistry.
+func NewRegistry() *Registry {
+	return &Registry{
+		ids:      make(map[reflect.Type]string),
+		types:    make(map[string]reflect.Type),
+		decoders: make(map[string]DecodeFunc),
+	}
+}
+
+// Register adds the error type T to the global registry, see RegisterTo.
+func Register[T error](id string, decode DecodeFunc) {
+	RegisterTo[T](defaultRegistry, id, decode)
+}
+
+// RegisterTo adds the error type T to the registry, replacing any previous registration of T or id.
+// Errors of exactly type T are encoded with id, and layers with id are decoded with decode.
+// The identifier should be stable across versions of the program, unlike the Go type name.
+func RegisterTo[T error](r *Registry, id string, decode DecodeFunc) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if oldID, ok := r.ids[t]; ok {
+		delete(r.types, oldID)
+		delete(r.decoders, oldID)
+	}
+	if oldT, ok := r.types[id]; ok {
+		delete(r.ids, oldT)
+	}
+
+	r.ids[t] = id
+	r.types[id] = t
+	r.decoders[id] = decode
+}
+
+// id returns the identifier err is encoded with.
+func (r *Registry) id(err error) string {
+	if dErr, ok := err.(interface{ decodedType() string }); ok {
+		return dErr.decodedType()
+	}
+
+	t := reflect.TypeOf(err)
+
+	r.mux.RLock()
+	id, ok := r.ids[t]
+	r.mux.RUnlock()
+
+	if !ok {
+		id = t.String()
+	}
+	return id
+}
+
+// decoder returns the DecodeFunc registered for id, if any.
+func (r *Registry) decoder(id string) (DecodeFunc, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	decode, ok := r.decoders[id]
+	return decode, ok
+}